@@ -0,0 +1 @@
+DELETE FROM request_type WHERE description IN ('Geoapify Geocoding API');
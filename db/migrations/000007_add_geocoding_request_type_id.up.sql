@@ -0,0 +1 @@
+INSERT INTO request_type (description) VALUES ('Geoapify Geocoding API');
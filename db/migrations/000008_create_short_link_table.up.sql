@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS short_link (
+    short_url TEXT PRIMARY KEY,
+    resolved_url TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT now(),
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_short_link_expires_at ON short_link (expires_at);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS conversion_cache (
+    cache_key TEXT PRIMARY KEY,
+    response JSONB,
+    error TEXT,
+    created_at TIMESTAMP DEFAULT now(),
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversion_cache_expires_at ON conversion_cache (expires_at);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS place (
+    cid TEXT PRIMARY KEY,
+    ftid TEXT,
+    name TEXT,
+    lat DOUBLE PRECISION NOT NULL,
+    lng DOUBLE PRECISION NOT NULL,
+    first_seen TIMESTAMP DEFAULT now(),
+    last_verified TIMESTAMP DEFAULT now()
+);
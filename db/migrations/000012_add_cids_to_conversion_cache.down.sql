@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_conversion_cache_cids;
+
+ALTER TABLE conversion_cache DROP COLUMN IF EXISTS cids;
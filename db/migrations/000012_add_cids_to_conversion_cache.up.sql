@@ -0,0 +1,3 @@
+ALTER TABLE conversion_cache ADD COLUMN IF NOT EXISTS cids TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_conversion_cache_cids ON conversion_cache USING GIN (cids);
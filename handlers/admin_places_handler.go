@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"maps-to-waze-api/models"
+	"maps-to-waze-api/services"
+	"net/http"
+	"strconv"
+)
+
+func (app *App) GetAdminPlaces(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	limit, offset := 100, 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > 1000 {
+			http.Error(w, "limit must be between 1 and 1000", http.StatusBadRequest)
+			return
+		}
+	}
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		var err error
+		offset, err = strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			http.Error(w, "offset must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	data, err := app.Service.ListPlaces(ctx, limit, offset)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list the places", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	writeAdminJson(w, r, data)
+}
+
+func (app *App) PutAdminPlace(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var requestData models.PlaceUpdateRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := app.Service.UpdatePlace(ctx, r.PathValue("cid"), requestData)
+	if errors.Is(err, services.ErrPlaceNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeAdminJson(w, r, data)
+}
+
+func writeAdminJson(w http.ResponseWriter, r *http.Request, data any) {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error marshaling JSON:", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(jsonData)
+
+	if err != nil {
+		slog.ErrorContext(r.Context(), "error writing response:", "error", err)
+	}
+}
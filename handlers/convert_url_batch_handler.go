@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps-to-waze-api/models"
+	"net/http"
+	"strconv"
+)
+
+func (app *App) PostConvertUrlBatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var requestData models.ConvertUrlBatchRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	targets := parseTargets(r.URL.Query().Get("targets"))
+
+	data, err := app.Service.ConvertUrlBatch(ctx, requestData.URLs, targets)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if debug, _ := strconv.ParseBool(r.URL.Query().Get("debug")); !debug {
+		for _, item := range data.Results {
+			if item.Result != nil {
+				removeDebug(item.Result)
+			}
+		}
+	}
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		slog.ErrorContext(ctx, "error marshaling JSON:", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	slog.DebugContext(ctx, fmt.Sprintf("converted a batch of %d URLs", len(data.Results)))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(jsonData)
+
+	if err != nil {
+		slog.ErrorContext(ctx, "error writing response:", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"log/slog"
 	"maps-to-waze-api/models"
+	"maps-to-waze-api/services"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 func (app *App) PostConvertUrl(w http.ResponseWriter, r *http.Request) {
@@ -17,13 +20,30 @@ func (app *App) PostConvertUrl(w http.ResponseWriter, r *http.Request) {
         return 
     }
 
-    var data, err = app.Service.ConvertUrl(ctx, requestData.URL)
+    targets := parseTargets(r.URL.Query().Get("targets"))
+
+    var data models.ConvertUrlResponse
+    var err error
+    if requestData.Text != "" {
+        data, err = app.Service.ConvertText(ctx, requestData.Text, targets)
+    } else {
+        data, err = app.Service.ConvertUrl(ctx, requestData.URL, targets)
+    }
 
     if err != nil {
-        http.Error(w, err.Error(), http.StatusBadRequest)
+        status := http.StatusBadRequest
+        if services.IsRedirectRejection(err) {
+            status = http.StatusForbidden
+        }
+        http.Error(w, err.Error(), status)
         return
     }
 
+    // The debug section is only returned when asked for
+    if debug, _ := strconv.ParseBool(r.URL.Query().Get("debug")); !debug {
+        removeDebug(&data)
+    }
+
     jsonData, err := json.Marshal(data)
 
     if err != nil {
@@ -44,3 +64,24 @@ func (app *App) PostConvertUrl(w http.ResponseWriter, r *http.Request) {
         return
     }
 }
+
+// parseTargets splits ?targets=waze, apple into its trimmed, non-empty entries
+func parseTargets(targetsStr string) []string {
+    var targets []string
+    for _, target := range strings.Split(targetsStr, ",") {
+        if target = strings.TrimSpace(target); target != "" {
+            targets = append(targets, target)
+        }
+    }
+
+    return targets
+}
+
+func removeDebug(data *models.ConvertUrlResponse) {
+    data.Debug = nil
+    for _, match := range data.Matches {
+        if match.Result != nil {
+            removeDebug(match.Result)
+        }
+    }
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+func (app *App) GetConvertCoordinates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	text := r.URL.Query().Get("q")
+	latitudeStr := r.URL.Query().Get("lat")
+	longitudeStr := r.URL.Query().Get("lon")
+
+	if text == "" {
+		if latitudeStr == "" || longitudeStr == "" {
+			http.Error(w, "Missing q or latitude and longitude", http.StatusBadRequest)
+			return
+		}
+		text = fmt.Sprintf("%s, %s", latitudeStr, longitudeStr)
+	}
+
+	data, err := app.Service.ConvertCoordinates(ctx, text)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		slog.ErrorContext(ctx, "error marshaling JSON:", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(jsonData)
+
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+}
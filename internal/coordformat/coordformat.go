@@ -0,0 +1,47 @@
+package coordformat
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Format string
+
+const (
+	DecimalDegrees        Format = "dd"
+	DegreesMinutesSeconds Format = "dms"
+	DegreesDecimalMinutes Format = "ddm"
+	UTM                   Format = "utm"
+	MGRS                  Format = "mgrs"
+)
+
+// Point is a WGS84 position in decimal degrees
+type Point struct {
+	Latitude  float64
+	Longitude float64
+}
+
+func (p Point) Valid() bool {
+	return p.Latitude >= -90 && p.Latitude <= 90 && p.Longitude >= -180 && p.Longitude <= 180
+}
+
+// Parse reads a position written in any of the supported formats and
+// reports which one it was
+func Parse(text string) (Point, Format, error) {
+	text = strings.TrimSpace(text)
+
+	if point, err := ParseMGRS(text); err == nil {
+		return point, MGRS, nil
+	}
+
+	if point, err := ParseUTM(text); err == nil {
+		return point, UTM, nil
+	}
+
+	point, format, err := parseGeographic(text)
+	if err != nil {
+		return Point{}, "", fmt.Errorf("failed to parse coordinates from %q", text)
+	}
+
+	return point, format, nil
+}
@@ -0,0 +1,225 @@
+package coordformat
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text   string
+		want   Point
+		format Format
+	}{
+		{"45.4642, 9.1900", Point{45.4642, 9.19}, DecimalDegrees},
+		{"-33.8568 151.2153", Point{-33.8568, 151.2153}, DecimalDegrees},
+		{"45 9", Point{45, 9}, DecimalDegrees},
+		{"1 2", Point{1, 2}, DecimalDegrees},
+		{"12 34", Point{12, 34}, DecimalDegrees},
+		{"-12 -34", Point{-12, -34}, DecimalDegrees},
+		{"45 30 9 15", Point{45.5, 9.25}, DegreesDecimalMinutes},
+		{"45 30 15 9 10 20", Point{45 + 30.0/60 + 15.0/3600, 9 + 10.0/60 + 20.0/3600}, DegreesMinutesSeconds},
+		{"N 45.4642 E 9.19", Point{45.4642, 9.19}, DecimalDegrees},
+		{"9.19E 45.4642N", Point{45.4642, 9.19}, DecimalDegrees},
+		{`45°27'51.1"N 9°11'24.0"E`, Point{45 + 27.0/60 + 51.1/3600, 9 + 11.0/60 + 24.0/3600}, DegreesMinutesSeconds},
+		{`33°51'24.5"S 151°12'55.1"E`, Point{-(33 + 51.0/60 + 24.5/3600), 151 + 12.0/60 + 55.1/3600}, DegreesMinutesSeconds},
+		{"45°27.852'N 9°11.400'E", Point{45 + 27.852/60, 9 + 11.4/60}, DegreesDecimalMinutes},
+		{"31N 166021 0", Point{0, 0}, UTM},
+		{"31N AA 66021 00000", Point{0, 0}, MGRS},
+	}
+
+	for _, test := range tests {
+		got, format, err := Parse(test.text)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", test.text, err)
+			continue
+		}
+		if format != test.format || metersApart(got, test.want) > 1 {
+			t.Errorf("Parse(%q) = %v %s, want %v %s", test.text, got, format, test.want, test.format)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"45",
+		"45 9 12",
+		"91 9",
+		"45 -30 9 15",
+		"45 75 9 15",
+		"45.5 9d30'",
+		"N 45.5 N 9.5",
+		"-45.5S 9.5E",
+		"31N 50000 0",
+		"31N IA 66021 00000",
+		"31N AA 6602 00000",
+	} {
+		if point, format, err := Parse(text); err == nil {
+			t.Errorf("Parse(%q) = %v %s, want an error", text, point, format)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	point := Point{Latitude: -33.856800, Longitude: 151.215300}
+
+	if got := FormatDecimalDegrees(point); got != "-33.856800, 151.215300" {
+		t.Errorf("FormatDecimalDegrees() = %q", got)
+	}
+	if got := FormatDegreesMinutesSeconds(point); got != `33°51'24.5"S 151°12'55.1"E` {
+		t.Errorf("FormatDegreesMinutesSeconds() = %q", got)
+	}
+	if got := FormatDegreesDecimalMinutes(point); got != "33°51.408'S 151°12.918'E" {
+		t.Errorf("FormatDegreesDecimalMinutes() = %q", got)
+	}
+
+	// Rounding must carry into the minutes instead of printing 60 seconds
+	if got := FormatDegreesMinutesSeconds(Point{Latitude: 45.99999, Longitude: 9}); got != `46°00'00.0"N 9°00'00.0"E` {
+		t.Errorf("FormatDegreesMinutesSeconds() = %q", got)
+	}
+}
+
+func TestUTM(t *testing.T) {
+	tests := []struct {
+		point Point
+		want  string
+	}{
+		{Point{0, 0}, "31N 166021 0"},
+		{Point{0, -180}, "1N 166021 0"},
+		{Point{0, 179.999}, "60N 833867 0"},
+		{Point{-0.000001, 3}, "31M 500000 9999999"},
+		// Norway and Svalbard exceptions
+		{Point{60, 5}, "32V 276979 6658157"},
+		{Point{78, 15}, "33X 500000 8658369"},
+		{Point{78, 8}, "31X 615914 8663320"},
+	}
+
+	for _, test := range tests {
+		got, err := FormatUTM(test.point)
+		if err != nil {
+			t.Errorf("FormatUTM(%v) failed: %v", test.point, err)
+			continue
+		}
+		if !sameFields(got, test.want, 1) {
+			t.Errorf("FormatUTM(%v) = %q, want %q", test.point, got, test.want)
+		}
+	}
+
+	for _, latitude := range []float64{-80.5, 84.5} {
+		if got, err := FormatUTM(Point{latitude, 0}); err == nil {
+			t.Errorf("FormatUTM() at latitude %v = %q, want an error", latitude, got)
+		}
+	}
+}
+
+func TestMGRS(t *testing.T) {
+	tests := []struct {
+		point Point
+		want  string
+	}{
+		{Point{0, 0}, "31N AA 66021 00000"},
+		// Even zones shift the row letters by five
+		{Point{-33.8568, 151.2153}, "56H LH"},
+		{Point{45.4642, 9.19}, "32T NR"},
+		{Point{-54.8, -68.3}, "19F EV"},
+		{Point{40.6892, -74.0445}, "18T WL"},
+	}
+
+	for _, test := range tests {
+		got, err := FormatMGRS(test.point)
+		if err != nil {
+			t.Errorf("FormatMGRS(%v) failed: %v", test.point, err)
+			continue
+		}
+		if !strings.HasPrefix(got, test.want) {
+			t.Errorf("FormatMGRS(%v) = %q, want %q", test.point, got, test.want)
+		}
+	}
+
+	// Fewer digits describe a bigger square, the center is returned
+	point, err := ParseMGRS("31NAA6602")
+	if err != nil || metersApart(point, Point{0.0226, 0.0043}) > 50 {
+		t.Errorf("ParseMGRS(31NAA6602) = %v %v", point, err)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	points := []Point{
+		{45.4642, 9.19},
+		{-33.8568, 151.2153},
+		{-54.8019, -68.303},
+		{64.1466, -21.9426},
+		{-0.5, -179.5},
+		{0.5, 179.5},
+		{59.9, 5.9},
+		{79.5, 20.9},
+		{-79.9, 0.1},
+		{83.9, -30},
+	}
+
+	for _, point := range points {
+		for _, format := range []struct {
+			name      string
+			tolerance float64
+			format    func(Point) (string, error)
+		}{
+			{"dd", 0.2, func(p Point) (string, error) { return FormatDecimalDegrees(p), nil }},
+			{"dms", 2, func(p Point) (string, error) { return FormatDegreesMinutesSeconds(p), nil }},
+			{"ddm", 1, func(p Point) (string, error) { return FormatDegreesDecimalMinutes(p), nil }},
+			{"utm", 2, FormatUTM},
+			{"mgrs", 2, FormatMGRS},
+		} {
+			text, err := format.format(point)
+			if err != nil {
+				t.Errorf("%s of %v failed: %v", format.name, point, err)
+				continue
+			}
+
+			got, parsed, err := Parse(text)
+			if err != nil {
+				t.Errorf("Parse(%q) failed: %v", text, err)
+				continue
+			}
+			if string(parsed) != format.name || metersApart(got, point) > format.tolerance {
+				t.Errorf("Parse(%q) = %v %s, want %v %s", text, got, parsed, point, format.name)
+			}
+		}
+	}
+}
+
+// metersApart is good enough for the short distances compared here, a
+// degree of longitude shrinks towards the poles
+func metersApart(a Point, b Point) float64 {
+	const metersPerDegree = 111320
+	latitude := (a.Latitude - b.Latitude) * metersPerDegree
+	longitude := (a.Longitude - b.Longitude) * metersPerDegree * math.Cos(a.Latitude*math.Pi/180)
+
+	return math.Hypot(latitude, longitude)
+}
+
+// sameFields compares "zone easting northing", allowing the numbers to be
+// off by the tolerance in meters
+func sameFields(got string, want string, tolerance float64) bool {
+	gotFields, wantFields := strings.Fields(got), strings.Fields(want)
+	if len(gotFields) != 3 || len(wantFields) != 3 || gotFields[0] != wantFields[0] {
+		return false
+	}
+
+	for i := 1; i < 3; i++ {
+		var g, w float64
+		if _, err := fmt.Sscan(gotFields[i], &g); err != nil {
+			return false
+		}
+		if _, err := fmt.Sscan(wantFields[i], &w); err != nil {
+			return false
+		}
+		if math.Abs(g-w) > tolerance {
+			return false
+		}
+	}
+
+	return true
+}
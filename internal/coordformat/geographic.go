@@ -0,0 +1,197 @@
+package coordformat
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// One coordinate: an optional hemisphere letter or sign, then either
+// fractional degrees, or whole degrees followed by fractional minutes, or
+// whole degrees and minutes followed by seconds
+const componentPattern = `([NSEWnsew])?\s*([-+])?` +
+	`(?:(\d{1,3}\.\d+)\s*(?:°|º|d)?` +
+	`|(\d{1,3})\s*(?:°|º|d)?` +
+	`(?:\s*(?:(\d{1,2}\.\d+)\s*(?:'|′|’)?` +
+	`|(\d{1,2})\s*(?:'|′|’)?(?:\s*(\d{1,2}(?:\.\d+)?)\s*(?:"|″|”|'')?)?))?)` +
+	`\s*([NSEWnsew])?`
+
+var geographicPairPattern = regexp.MustCompile(`^\s*` + componentPattern + `\s*[,;/]?\s*` + componentPattern + `\s*$`)
+
+// Whitespace-separated integers without any unit, the pattern above would
+// split them in several ways
+var integerRunPattern = regexp.MustCompile(`^[-+]?\d+(?:\s+[-+]?\d+)*$`)
+
+type component struct {
+	hemisphere string
+	value      float64
+	format     Format
+}
+
+// parseGeographic reads decimal degrees, degrees and decimal minutes or
+// degrees, minutes and seconds, with signs or hemisphere letters
+func parseGeographic(text string) (Point, Format, error) {
+	if integerRunPattern.MatchString(text) {
+		return parseIntegerRun(strings.Fields(text))
+	}
+
+	match := geographicPairPattern.FindStringSubmatch(text)
+	if match == nil {
+		return Point{}, "", fmt.Errorf("not a geographic coordinate pair")
+	}
+
+	// In "N 45.46 E 9.19" the E is taken as the suffix of the latitude,
+	// give it back to the longitude
+	if match[1] != "" && match[8] != "" && match[9] == "" {
+		match[8], match[9] = "", match[8]
+	}
+
+	first, err := parseComponent(match[1:9])
+	if err != nil {
+		return Point{}, "", err
+	}
+	second, err := parseComponent(match[9:17])
+	if err != nil {
+		return Point{}, "", err
+	}
+
+	if first.format != second.format {
+		return Point{}, "", fmt.Errorf("latitude and longitude use different formats")
+	}
+
+	// Without hemisphere letters the latitude comes first
+	latitude, longitude := first, second
+	if strings.ContainsAny(first.hemisphere, "EW") || strings.ContainsAny(second.hemisphere, "NS") {
+		latitude, longitude = second, first
+	}
+	if strings.ContainsAny(latitude.hemisphere, "EW") || strings.ContainsAny(longitude.hemisphere, "NS") {
+		return Point{}, "", fmt.Errorf("inconsistent hemisphere letters")
+	}
+
+	point := Point{Latitude: latitude.value, Longitude: longitude.value}
+	if !point.Valid() {
+		return Point{}, "", fmt.Errorf("coordinates out of range")
+	}
+
+	return point, first.format, nil
+}
+
+// parseIntegerRun splits bare integers evenly between latitude and
+// longitude: two are decimal degrees, four degrees and minutes, six
+// degrees, minutes and seconds
+func parseIntegerRun(fields []string) (Point, Format, error) {
+	var format Format
+	switch len(fields) {
+	case 2:
+		format = DecimalDegrees
+	case 4:
+		format = DegreesDecimalMinutes
+	case 6:
+		format = DegreesMinutesSeconds
+	default:
+		return Point{}, "", fmt.Errorf("%d numbers can't be split into latitude and longitude", len(fields))
+	}
+
+	half := len(fields) / 2
+	var values [2]float64
+	for i := range values {
+		unit := 1.0
+		for j, field := range fields[i*half : (i+1)*half] {
+			if j > 0 && strings.ContainsAny(field, "-+") {
+				return Point{}, "", fmt.Errorf("only degrees can have a sign")
+			}
+			number, _ := strconv.ParseFloat(strings.TrimLeft(field, "-+"), 64)
+			if j > 0 && number >= 60 {
+				return Point{}, "", fmt.Errorf("minutes or seconds out of range")
+			}
+			values[i] += number / unit
+			unit *= 60
+		}
+		if strings.HasPrefix(fields[i*half], "-") {
+			values[i] = -values[i]
+		}
+	}
+
+	point := Point{Latitude: values[0], Longitude: values[1]}
+	if !point.Valid() {
+		return Point{}, "", fmt.Errorf("coordinates out of range")
+	}
+
+	return point, format, nil
+}
+
+// parseComponent takes the prefix hemisphere, sign, fractional degrees,
+// whole degrees, fractional minutes, whole minutes, seconds and suffix
+// hemisphere groups
+func parseComponent(groups []string) (component, error) {
+	if groups[0] != "" && groups[7] != "" {
+		return component{}, fmt.Errorf("hemisphere given twice")
+	}
+	hemisphere := strings.ToUpper(groups[0] + groups[7])
+
+	degrees, _ := strconv.ParseFloat(groups[2]+groups[3], 64)
+	minutes, _ := strconv.ParseFloat(groups[4]+groups[5], 64)
+	seconds, _ := strconv.ParseFloat(groups[6], 64)
+	if minutes >= 60 || seconds >= 60 {
+		return component{}, fmt.Errorf("minutes or seconds out of range")
+	}
+
+	value, format := degrees+minutes/60+seconds/3600, DecimalDegrees
+	switch {
+	case groups[6] != "":
+		format = DegreesMinutesSeconds
+	case groups[4] != "" || groups[5] != "":
+		format = DegreesDecimalMinutes
+	}
+
+	if groups[1] == "-" && hemisphere != "" {
+		return component{}, fmt.Errorf("both a sign and a hemisphere letter")
+	}
+	if groups[1] == "-" || hemisphere == "S" || hemisphere == "W" {
+		value = -value
+	}
+
+	return component{hemisphere: hemisphere, value: value, format: format}, nil
+}
+
+func FormatDecimalDegrees(p Point) string {
+	return fmt.Sprintf("%s, %s", strconv.FormatFloat(p.Latitude, 'f', 6, 64), strconv.FormatFloat(p.Longitude, 'f', 6, 64))
+}
+
+func FormatDegreesMinutesSeconds(p Point) string {
+	return fmt.Sprintf("%s %s", formatDMS(p.Latitude, "N", "S"), formatDMS(p.Longitude, "E", "W"))
+}
+
+func FormatDegreesDecimalMinutes(p Point) string {
+	return fmt.Sprintf("%s %s", formatDDM(p.Latitude, "N", "S"), formatDDM(p.Longitude, "E", "W"))
+}
+
+func formatDMS(value float64, positive string, negative string) string {
+	hemisphere := positive
+	if value < 0 {
+		hemisphere = negative
+	}
+
+	// Round on tenths of a second first so that 59.96" does not show up as 60.0"
+	tenths := math.Round(math.Abs(value) * 36000)
+	degrees := math.Floor(tenths / 36000)
+	minutes := math.Floor((tenths - degrees*36000) / 600)
+	seconds := (tenths - degrees*36000 - minutes*600) / 10
+
+	return fmt.Sprintf("%.0f°%02.0f'%04.1f\"%s", degrees, minutes, seconds, hemisphere)
+}
+
+func formatDDM(value float64, positive string, negative string) string {
+	hemisphere := positive
+	if value < 0 {
+		hemisphere = negative
+	}
+
+	thousandths := math.Round(math.Abs(value) * 60000)
+	degrees := math.Floor(thousandths / 60000)
+	minutes := (thousandths - degrees*60000) / 1000
+
+	return fmt.Sprintf("%.0f°%06.3f'%s", degrees, minutes, hemisphere)
+}
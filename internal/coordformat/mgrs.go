@@ -0,0 +1,122 @@
+package coordformat
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 100 km square letters, the column set repeats every three zones and the
+// row letters are shifted by five on even zones
+var (
+	mgrsColumnSets = [3]string{"ABCDEFGH", "JKLMNPQR", "STUVWXYZ"}
+	mgrsRowLetters = "ABCDEFGHJKLMNPQRSTUV"
+)
+
+var mgrsPattern = regexp.MustCompile(`(?i)^(\d{1,2})\s*([C-HJ-NP-X])\s*([A-HJ-NP-Z])([A-HJ-NP-V])\s*(\d*)\s*(\d*)$`)
+
+func FormatMGRS(p Point) (string, error) {
+	u, err := ToUTM(p)
+	if err != nil {
+		return "", err
+	}
+
+	column := int(math.Floor(u.Easting / 100000))
+	row := int(math.Floor(u.Northing / 100000))
+	columnLetter := mgrsColumnSets[(u.Zone-1)%3][column-1]
+	rowLetter := mgrsRowLetters[(row+mgrsRowOffset(u.Zone))%len(mgrsRowLetters)]
+
+	easting := int(math.Floor(u.Easting)) % 100000
+	northing := int(math.Floor(u.Northing)) % 100000
+
+	return fmt.Sprintf("%d%c %c%c %05d %05d", u.Zone, u.Band, columnLetter, rowLetter, easting, northing), nil
+}
+
+// ParseMGRS returns the center of the square described by the reference,
+// which gets smaller the more digits are given
+func ParseMGRS(text string) (Point, error) {
+	match := mgrsPattern.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return Point{}, fmt.Errorf("not an MGRS reference")
+	}
+
+	zone, _ := strconv.Atoi(match[1])
+	if zone < 1 || zone > 60 {
+		return Point{}, fmt.Errorf("invalid MGRS zone %d", zone)
+	}
+	band := strings.ToUpper(match[2])[0]
+	columnLetter := strings.ToUpper(match[3])[0]
+	rowLetter := strings.ToUpper(match[4])[0]
+
+	// The digits are either split already or written as one even run
+	eastingDigits, northingDigits := match[5], match[6]
+	if northingDigits == "" {
+		if len(eastingDigits)%2 != 0 {
+			return Point{}, fmt.Errorf("MGRS reference has an odd number of digits")
+		}
+		half := len(eastingDigits) / 2
+		eastingDigits, northingDigits = eastingDigits[:half], eastingDigits[half:]
+	}
+	if len(eastingDigits) != len(northingDigits) || len(eastingDigits) > 5 {
+		return Point{}, fmt.Errorf("MGRS easting and northing must have the same number of digits, up to five")
+	}
+
+	column := strings.IndexByte(mgrsColumnSets[(zone-1)%3], columnLetter)
+	if column < 0 {
+		return Point{}, fmt.Errorf("invalid MGRS column letter %c for zone %d", columnLetter, zone)
+	}
+	row := strings.IndexByte(mgrsRowLetters, rowLetter)
+	row = (row - mgrsRowOffset(zone) + len(mgrsRowLetters)) % len(mgrsRowLetters)
+
+	size := math.Pow(10, float64(5-len(eastingDigits)))
+	easting := float64(column+1)*100000 + digitsValue(eastingDigits)*size + size/2
+	northing := float64(row)*100000 + digitsValue(northingDigits)*size + size/2
+
+	// The row letters repeat every 2000 km, move up until we are in the band
+	bandNorthing, err := mgrsBandNorthing(zone, band)
+	if err != nil {
+		return Point{}, err
+	}
+	for northing < bandNorthing {
+		northing += 2000000
+	}
+
+	return FromUTM(UTMCoordinate{Zone: zone, Band: band, Easting: easting, Northing: northing})
+}
+
+func mgrsRowOffset(zone int) int {
+	if zone%2 == 0 {
+		return 5
+	}
+
+	return 0
+}
+
+func digitsValue(digits string) float64 {
+	if digits == "" {
+		return 0
+	}
+	value, _ := strconv.Atoi(digits)
+
+	return float64(value)
+}
+
+// mgrsBandNorthing is the lowest northing of a latitude band in a zone,
+// rounded down to the 100 km square that contains it
+func mgrsBandNorthing(zone int, band byte) (float64, error) {
+	index := strings.IndexByte(latitudeBands, band)
+	if index < 0 {
+		return 0, fmt.Errorf("invalid MGRS band %c", band)
+	}
+	latitude := float64(index*8 - 80)
+
+	// Parallels bend towards the pole, so the lowest northing is found on the
+	// central meridian in the north and on the zone edge in the south
+	centralMeridian := float64(zone*6 - 183)
+	_, atMeridian := projectUTM(Point{Latitude: latitude, Longitude: centralMeridian}, zone)
+	_, atEdge := projectUTM(Point{Latitude: latitude, Longitude: centralMeridian + 3}, zone)
+
+	return math.Floor(math.Min(atMeridian, atEdge)/100000) * 100000, nil
+}
@@ -0,0 +1,200 @@
+package coordformat
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// WGS84 ellipsoid and UTM constants
+const (
+	wgs84A          = 6378137.0
+	wgs84F          = 1 / 298.257223563
+	utmScale        = 0.9996
+	utmFalseEasting = 500000.0
+	utmFalseNorth   = 10000000.0
+)
+
+const latitudeBands = "CDEFGHJKLMNPQRSTUVWXX"
+
+var utmPattern = regexp.MustCompile(`(?i)^(\d{1,2})\s*([C-HJ-NP-X])\s+(\d+(?:\.\d+)?)\s*(?:m\s*)?E?\s+(\d+(?:\.\d+)?)\s*(?:m\s*)?N?$`)
+
+type UTMCoordinate struct {
+	Zone     int
+	Band     byte
+	Easting  float64
+	Northing float64
+}
+
+func (u UTMCoordinate) String() string {
+	return fmt.Sprintf("%d%c %.0f %.0f", u.Zone, u.Band, math.Floor(u.Easting), math.Floor(u.Northing))
+}
+
+// Krüger series coefficients, see Karney "Transverse Mercator with an
+// accuracy of a few nanometers"
+var (
+	utmN     = wgs84F / (2 - wgs84F)
+	utmBigA  = wgs84A / (1 + utmN) * (1 + utmN*utmN/4 + math.Pow(utmN, 4)/64)
+	utmAlpha = [3]float64{
+		utmN/2 - 2*utmN*utmN/3 + 5*math.Pow(utmN, 3)/16,
+		13*utmN*utmN/48 - 3*math.Pow(utmN, 3)/5,
+		61 * math.Pow(utmN, 3) / 240,
+	}
+	utmBeta = [3]float64{
+		utmN/2 - 2*utmN*utmN/3 + 37*math.Pow(utmN, 3)/96,
+		utmN*utmN/48 + math.Pow(utmN, 3)/15,
+		17 * math.Pow(utmN, 3) / 480,
+	}
+	utmDelta = [3]float64{
+		2*utmN - 2*utmN*utmN/3 - 2*math.Pow(utmN, 3),
+		7*utmN*utmN/3 - 8*math.Pow(utmN, 3)/5,
+		56 * math.Pow(utmN, 3) / 15,
+	}
+)
+
+func ToUTM(p Point) (UTMCoordinate, error) {
+	if !p.Valid() || p.Latitude < -80 || p.Latitude > 84 {
+		return UTMCoordinate{}, fmt.Errorf("latitude %f is outside the UTM range", p.Latitude)
+	}
+
+	zone := utmZone(p)
+	easting, northing := projectUTM(p, zone)
+
+	return UTMCoordinate{Zone: zone, Band: latitudeBand(p.Latitude), Easting: easting, Northing: northing}, nil
+}
+
+func FromUTM(u UTMCoordinate) (Point, error) {
+	band := strings.IndexByte(latitudeBands, u.Band)
+	if u.Zone < 1 || u.Zone > 60 || band < 0 {
+		return Point{}, fmt.Errorf("invalid UTM zone %d%c", u.Zone, u.Band)
+	}
+
+	northing := u.Northing
+	if u.Band < 'N' {
+		northing -= utmFalseNorth
+	}
+
+	xi := northing / (utmScale * utmBigA)
+	eta := (u.Easting - utmFalseEasting) / (utmScale * utmBigA)
+
+	xiPrime, etaPrime := xi, eta
+	for j, beta := range utmBeta {
+		k := float64(2 * (j + 1))
+		xiPrime -= beta * math.Sin(k*xi) * math.Cosh(k*eta)
+		etaPrime -= beta * math.Cos(k*xi) * math.Sinh(k*eta)
+	}
+
+	chi := math.Asin(math.Sin(xiPrime) / math.Cosh(etaPrime))
+	latitude := chi
+	for j, delta := range utmDelta {
+		latitude += delta * math.Sin(float64(2*(j+1))*chi)
+	}
+	longitude := centralMeridian(u.Zone) + math.Atan2(math.Sinh(etaPrime), math.Cos(xiPrime))
+
+	point := Point{Latitude: latitude * 180 / math.Pi, Longitude: longitude * 180 / math.Pi}
+	if point.Longitude > 180 {
+		point.Longitude -= 360
+	} else if point.Longitude < -180 {
+		point.Longitude += 360
+	}
+	if !point.Valid() {
+		return Point{}, fmt.Errorf("UTM coordinate outside the world")
+	}
+
+	return point, nil
+}
+
+func ParseUTM(text string) (Point, error) {
+	match := utmPattern.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return Point{}, fmt.Errorf("not a UTM coordinate")
+	}
+
+	zone, _ := strconv.Atoi(match[1])
+	easting, _ := strconv.ParseFloat(match[3], 64)
+	northing, _ := strconv.ParseFloat(match[4], 64)
+	if easting < 100000 || easting > 900000 || northing > utmFalseNorth {
+		return Point{}, fmt.Errorf("UTM easting or northing out of range")
+	}
+
+	return FromUTM(UTMCoordinate{Zone: zone, Band: strings.ToUpper(match[2])[0], Easting: easting, Northing: northing})
+}
+
+func FormatUTM(p Point) (string, error) {
+	u, err := ToUTM(p)
+	if err != nil {
+		return "", err
+	}
+
+	return u.String(), nil
+}
+
+func projectUTM(p Point, zone int) (float64, float64) {
+	phi := p.Latitude * math.Pi / 180
+	lambda := p.Longitude*math.Pi/180 - centralMeridian(zone)
+
+	c := 2 * math.Sqrt(utmN) / (1 + utmN)
+	t := math.Sinh(math.Atanh(math.Sin(phi)) - c*math.Atanh(c*math.Sin(phi)))
+	xiPrime := math.Atan2(t, math.Cos(lambda))
+	etaPrime := math.Atanh(math.Sin(lambda) / math.Sqrt(1+t*t))
+
+	xi, eta := xiPrime, etaPrime
+	for j, alpha := range utmAlpha {
+		k := float64(2 * (j + 1))
+		xi += alpha * math.Sin(k*xiPrime) * math.Cosh(k*etaPrime)
+		eta += alpha * math.Cos(k*xiPrime) * math.Sinh(k*etaPrime)
+	}
+
+	easting := utmFalseEasting + utmScale*utmBigA*eta
+	northing := utmScale * utmBigA * xi
+	if p.Latitude < 0 {
+		northing += utmFalseNorth
+	}
+
+	return easting, northing
+}
+
+// utmZone applies the Norway and Svalbard exceptions to the 6° zones
+func utmZone(p Point) int {
+	zone := int(math.Floor((p.Longitude+180)/6)) + 1
+	if zone > 60 {
+		zone = 60
+	}
+
+	if p.Latitude >= 56 && p.Latitude < 64 && p.Longitude >= 3 && p.Longitude < 12 {
+		return 32
+	}
+
+	if p.Latitude >= 72 && p.Latitude <= 84 && p.Longitude >= 0 && p.Longitude < 42 {
+		switch {
+		case p.Longitude < 9:
+			return 31
+		case p.Longitude < 21:
+			return 33
+		case p.Longitude < 33:
+			return 35
+		default:
+			return 37
+		}
+	}
+
+	return zone
+}
+
+func centralMeridian(zone int) float64 {
+	return float64(zone*6-183) * math.Pi / 180
+}
+
+func latitudeBand(latitude float64) byte {
+	index := int(math.Floor((latitude + 80) / 8))
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(latitudeBands) {
+		index = len(latitudeBands) - 1
+	}
+
+	return latitudeBands[index]
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// GetConversion returns a cached conversion if it has not expired, either
+// the response as JSON or the error of a failed conversion, and the CIDs of
+// the places it depends on
+func GetConversion(ctx context.Context, db *sql.DB, cacheKey string) ([]byte, string, []string, time.Time, bool, error) {
+	var response []byte
+	var errorMessage sql.NullString
+	var cids []string
+	var secondsLeft float64
+	err := db.QueryRowContext(
+		ctx,
+		"SELECT response, error, cids, EXTRACT(EPOCH FROM expires_at - now()) FROM conversion_cache WHERE cache_key = $1 AND expires_at > now()",
+		cacheKey,
+	).Scan(&response, &errorMessage, pq.Array(&cids), &secondsLeft)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, "", nil, time.Time{}, false, nil
+	}
+	if err != nil {
+		return nil, "", nil, time.Time{}, false, err
+	}
+
+	expiresAt := time.Now().Add(time.Duration(secondsLeft * float64(time.Second)))
+	return response, errorMessage.String, cids, expiresAt, true, nil
+}
+
+func UpsertConversion(ctx context.Context, db *sql.DB, cacheKey string, response string, errorMessage string, cids []string, ttl time.Duration) error {
+	if cacheKey == "" {
+		return fmt.Errorf("cache_key cannot be empty")
+	}
+
+	_, err := db.ExecContext(
+		ctx,
+		`INSERT INTO conversion_cache (cache_key, response, error, cids, expires_at) VALUES ($1, NULLIF($2, '')::jsonb, NULLIF($3, ''), $4, now() + $5 * interval '1 second')
+		ON CONFLICT (cache_key) DO UPDATE SET response = EXCLUDED.response, error = EXCLUDED.error, cids = EXCLUDED.cids, created_at = now(), expires_at = EXCLUDED.expires_at`,
+		cacheKey,
+		response,
+		errorMessage,
+		pq.Array(cids),
+		int64(ttl.Seconds()),
+	)
+
+	return err
+}
+
+// DeleteConversionsByCid deletes the conversions that depend on the place
+func DeleteConversionsByCid(ctx context.Context, db *sql.DB, cid string) (int64, error) {
+	result, err := db.ExecContext(ctx, "DELETE FROM conversion_cache WHERE $1 = ANY(cids)", cid)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func DeleteExpiredConversions(ctx context.Context, db *sql.DB) (int64, error) {
+	result, err := db.ExecContext(ctx, "DELETE FROM conversion_cache WHERE expires_at <= now()")
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps-to-waze-api/models"
+)
+
+const placeColumns = "cid, COALESCE(ftid, ''), COALESCE(name, ''), lat, lng, first_seen, last_verified"
+
+func scanPlace(row interface{ Scan(...any) error }) (models.Place, error) {
+	var place models.Place
+	err := row.Scan(&place.Cid, &place.Ftid, &place.Name, &place.Latitude, &place.Longitude, &place.FirstSeen, &place.LastVerified)
+
+	return place, err
+}
+
+func GetPlace(ctx context.Context, db *sql.DB, cid string) (models.Place, bool, error) {
+	place, err := scanPlace(db.QueryRowContext(ctx, "SELECT "+placeColumns+" FROM place WHERE cid = $1", cid))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Place{}, false, nil
+	}
+	if err != nil {
+		return models.Place{}, false, err
+	}
+
+	return place, true, nil
+}
+
+// UpsertPlace stores a place verified right now. A missing ftid or name
+// keeps the one already known.
+func UpsertPlace(ctx context.Context, db *sql.DB, place models.Place) error {
+	if place.Cid == "" {
+		return fmt.Errorf("cid cannot be empty")
+	}
+
+	_, err := db.ExecContext(
+		ctx,
+		`INSERT INTO place (cid, ftid, name, lat, lng) VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5)
+		ON CONFLICT (cid) DO UPDATE SET
+			ftid = COALESCE(EXCLUDED.ftid, place.ftid),
+			name = COALESCE(EXCLUDED.name, place.name),
+			lat = EXCLUDED.lat,
+			lng = EXCLUDED.lng,
+			last_verified = now()`,
+		place.Cid,
+		place.Ftid,
+		place.Name,
+		place.Latitude,
+		place.Longitude,
+	)
+
+	return err
+}
+
+func ListPlaces(ctx context.Context, db *sql.DB, limit int, offset int) ([]models.Place, error) {
+	rows, err := db.QueryContext(
+		ctx,
+		"SELECT "+placeColumns+" FROM place ORDER BY last_verified DESC LIMIT $1 OFFSET $2",
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	places := []models.Place{}
+	for rows.Next() {
+		place, err := scanPlace(rows)
+		if err != nil {
+			return nil, err
+		}
+		places = append(places, place)
+	}
+
+	return places, rows.Err()
+}
+
+// UpdatePlace applies a manual correction, the place counts as verified
+func UpdatePlace(ctx context.Context, db *sql.DB, cid string, update models.PlaceUpdateRequest) (models.Place, bool, error) {
+	place, err := scanPlace(db.QueryRowContext(
+		ctx,
+		`UPDATE place SET
+			ftid = COALESCE($2, ftid),
+			name = COALESCE($3, name),
+			lat = COALESCE($4, lat),
+			lng = COALESCE($5, lng),
+			last_verified = now()
+		WHERE cid = $1
+		RETURNING `+placeColumns,
+		cid,
+		update.Ftid,
+		update.Name,
+		update.Latitude,
+		update.Longitude,
+	))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Place{}, false, nil
+	}
+	if err != nil {
+		return models.Place{}, false, err
+	}
+
+	return place, true, nil
+}
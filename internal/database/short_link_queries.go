@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// GetShortLink returns the resolved URL of a short link if it has not expired
+func GetShortLink(ctx context.Context, db *sql.DB, shortUrl string) (string, bool, error) {
+	var resolvedUrl string
+	err := db.QueryRowContext(
+		ctx,
+		"SELECT resolved_url FROM short_link WHERE short_url = $1 AND expires_at > now()",
+		shortUrl,
+	).Scan(&resolvedUrl)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	return resolvedUrl, true, nil
+}
+
+func UpsertShortLink(ctx context.Context, db *sql.DB, shortUrl string, resolvedUrl string, ttl time.Duration) error {
+	if shortUrl == "" || resolvedUrl == "" {
+		return fmt.Errorf("short_url and resolved_url cannot be empty")
+	}
+
+	_, err := db.ExecContext(
+		ctx,
+		`INSERT INTO short_link (short_url, resolved_url, expires_at) VALUES ($1, $2, now() + $3 * interval '1 second')
+		ON CONFLICT (short_url) DO UPDATE SET resolved_url = EXCLUDED.resolved_url, created_at = now(), expires_at = EXCLUDED.expires_at`,
+		shortUrl,
+		resolvedUrl,
+		int64(ttl.Seconds()),
+	)
+
+	return err
+}
+
+func DeleteExpiredShortLinks(ctx context.Context, db *sql.DB) (int64, error) {
+	result, err := db.ExecContext(ctx, "DELETE FROM short_link WHERE expires_at <= now()")
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
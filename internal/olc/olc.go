@@ -0,0 +1,230 @@
+// Package olc implements Open Location Codes (plus codes), see
+// https://github.com/google/open-location-code/blob/main/Documentation/Specification/specification.md
+package olc
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	alphabet      = "23456789CFGHJMPQRVWX"
+	separator     = '+'
+	padding       = '0'
+	separatorPos  = 8
+	pairCodeLen   = 10
+	maxCodeLen    = 15
+	encodingBase  = 20
+	gridColumns   = 4
+	gridRows      = 5
+	latitudeMax   = 90
+	longitudeMax  = 180
+	pairPrecision = 8000 // 1 / the size in degrees of the last pair cell
+)
+
+const (
+	// The code length Google Maps shows, a cell of about 14x14 meters
+	DefaultCodeLen = 10
+	// Integer precision of a maximum length code
+	finalLatPrecision = pairPrecision * 3125 // gridRows^5
+	finalLngPrecision = pairPrecision * 1024 // gridColumns^5
+)
+
+type CodeArea struct {
+	LatLo, LngLo, LatHi, LngHi float64
+	Len                        int
+}
+
+func (area CodeArea) Center() (float64, float64) {
+	lat := math.Min((area.LatLo+area.LatHi)/2, latitudeMax)
+	lng := math.Min((area.LngLo+area.LngHi)/2, longitudeMax)
+
+	return lat, lng
+}
+
+func Encode(lat float64, lng float64, codeLen int) (string, error) {
+	if codeLen < 2 || (codeLen < pairCodeLen && codeLen%2 == 1) {
+		return "", fmt.Errorf("invalid plus code length %d", codeLen)
+	}
+	if codeLen > maxCodeLen {
+		codeLen = maxCodeLen
+	}
+
+	// Work on integers to avoid floating point drift
+	latVal := int64(math.Floor(math.Round((lat+latitudeMax)*finalLatPrecision*1e6) / 1e6))
+	lngVal := int64(math.Floor(math.Round((lng+longitudeMax)*finalLngPrecision*1e6) / 1e6))
+
+	latVal = max(latVal, 0)
+	if latVal >= 2*latitudeMax*finalLatPrecision {
+		latVal = 2*latitudeMax*finalLatPrecision - 1
+	}
+	lngRange := int64(2 * longitudeMax * finalLngPrecision)
+	lngVal = ((lngVal % lngRange) + lngRange) % lngRange
+
+	code := make([]byte, maxCodeLen)
+
+	// Grid digits, each splits the cell in 5 rows and 4 columns
+	for i := maxCodeLen - 1; i >= pairCodeLen; i-- {
+		code[i] = alphabet[(latVal%gridRows)*gridColumns+lngVal%gridColumns]
+		latVal /= gridRows
+		lngVal /= gridColumns
+	}
+
+	// Pair digits, alternating latitude and longitude in base 20
+	for i := pairCodeLen - 2; i >= 0; i -= 2 {
+		code[i] = alphabet[latVal%encodingBase]
+		code[i+1] = alphabet[lngVal%encodingBase]
+		latVal /= encodingBase
+		lngVal /= encodingBase
+	}
+
+	digits := string(code[:codeLen])
+	if codeLen < separatorPos {
+		return digits + strings.Repeat(string(padding), separatorPos-codeLen) + string(separator), nil
+	}
+
+	return digits[:separatorPos] + string(separator) + digits[separatorPos:], nil
+}
+
+func Decode(code string) (CodeArea, error) {
+	if !IsFull(code) {
+		return CodeArea{}, fmt.Errorf("%q is not a full plus code", code)
+	}
+
+	digits := cleanCode(code)
+	if len(digits) > maxCodeLen {
+		digits = digits[:maxCodeLen]
+	}
+
+	// Pairs first, in units of 1/pairPrecision degrees
+	var latVal, lngVal, size int64
+	placeValue := int64(encodingBase * pairPrecision)
+	for i := 0; i < pairCodeLen && i < len(digits); i += 2 {
+		latVal += int64(strings.IndexByte(alphabet, digits[i])) * placeValue
+		lngVal += int64(strings.IndexByte(alphabet, digits[i+1])) * placeValue
+		size = placeValue
+		placeValue /= encodingBase
+	}
+	latSize := float64(size) / pairPrecision
+	lngSize := latSize
+	lat := float64(latVal) / pairPrecision
+	lng := float64(lngVal) / pairPrecision
+
+	// Then the grid refinements
+	rowSize, columnSize := 1.0/pairPrecision, 1.0/pairPrecision
+	for i := pairCodeLen; i < len(digits); i++ {
+		index := strings.IndexByte(alphabet, digits[i])
+		rowSize /= gridRows
+		columnSize /= gridColumns
+		lat += float64(index/gridColumns) * rowSize
+		lng += float64(index%gridColumns) * columnSize
+		latSize, lngSize = rowSize, columnSize
+	}
+
+	return CodeArea{
+		LatLo: lat - latitudeMax,
+		LngLo: lng - longitudeMax,
+		LatHi: lat - latitudeMax + latSize,
+		LngHi: lng - longitudeMax + lngSize,
+		Len:   len(digits),
+	}, nil
+}
+
+// RecoverNearest turns a short code into the full code closest to the
+// reference position
+func RecoverNearest(code string, refLat float64, refLng float64) (string, error) {
+	if IsFull(code) {
+		return strings.ToUpper(code), nil
+	}
+	if !IsShort(code) {
+		return "", fmt.Errorf("%q is not a valid plus code", code)
+	}
+	code = strings.ToUpper(code)
+
+	paddingLen := separatorPos - strings.IndexByte(code, separator)
+	resolution := math.Pow(encodingBase, float64(2-paddingLen/2))
+	halfResolution := resolution / 2
+
+	refCode, err := Encode(refLat, refLng, maxCodeLen)
+	if err != nil {
+		return "", err
+	}
+	area, err := Decode(refCode[:paddingLen] + code)
+	if err != nil {
+		return "", err
+	}
+	lat, lng := area.Center()
+
+	// The prefix of the reference can point to the wrong neighbour cell
+	if refLat+halfResolution < lat && lat-resolution >= -latitudeMax {
+		lat -= resolution
+	} else if refLat-halfResolution > lat && lat+resolution <= latitudeMax {
+		lat += resolution
+	}
+	if refLng+halfResolution < lng {
+		lng -= resolution
+	} else if refLng-halfResolution > lng {
+		lng += resolution
+	}
+
+	return Encode(lat, lng, area.Len)
+}
+
+func IsValid(code string) bool {
+	code = strings.ToUpper(code)
+
+	separatorIndex := strings.IndexByte(code, separator)
+	if separatorIndex < 0 || separatorIndex != strings.LastIndexByte(code, separator) ||
+		separatorIndex > separatorPos || separatorIndex%2 == 1 {
+		return false
+	}
+
+	if paddingIndex := strings.IndexByte(code, padding); paddingIndex >= 0 {
+		// Padding is only allowed on full codes, in pairs, right before the separator
+		if separatorIndex < separatorPos || paddingIndex == 0 || paddingIndex%2 == 1 || paddingIndex > separatorIndex {
+			return false
+		}
+		if strings.Trim(code[paddingIndex:separatorIndex], string(padding)) != "" || separatorIndex != len(code)-1 {
+			return false
+		}
+	}
+
+	// A single digit after the separator is not allowed
+	if len(code)-separatorIndex-1 == 1 {
+		return false
+	}
+
+	for _, c := range strings.ReplaceAll(strings.ReplaceAll(code, string(separator), ""), string(padding), "") {
+		if !strings.ContainsRune(alphabet, c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func IsShort(code string) bool {
+	return IsValid(code) && strings.IndexByte(code, separator) < separatorPos
+}
+
+func IsFull(code string) bool {
+	if !IsValid(code) || IsShort(code) {
+		return false
+	}
+
+	code = strings.ToUpper(code)
+	if strings.IndexByte(alphabet, code[0])*encodingBase >= 2*latitudeMax {
+		return false
+	}
+	if len(code) > 1 && strings.IndexByte(alphabet, code[1])*encodingBase >= 2*longitudeMax {
+		return false
+	}
+
+	return true
+}
+
+func cleanCode(code string) string {
+	code = strings.ToUpper(strings.ReplaceAll(code, string(separator), ""))
+	return strings.TrimRight(code, string(padding))
+}
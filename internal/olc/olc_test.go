@@ -0,0 +1,62 @@
+package olc
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIsValid(t *testing.T) {
+	tests := []struct {
+		code  string
+		valid bool
+	}{
+		{"8FVC9G8F+6X", true},
+		{"8fvc9g8f+6x", true},
+		{"8FVC0000+", true},
+		{"9G8F+6X", true},
+		{"8FVC9G8F6X", false},
+		{"8FVC9G8F+6", false},
+		{"8FVC00+", false},
+		{"8FVC9G8F++6X", false},
+		{"22222222+00", false},
+		{"2222+0000", false},
+		{"8FVC9G8F+6A", false},
+	}
+
+	for _, test := range tests {
+		if got := IsValid(test.code); got != test.valid {
+			t.Errorf("IsValid(%q) = %v, want %v", test.code, got, test.valid)
+		}
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	code, err := Encode(47.365562, 8.524953, DefaultCodeLen)
+	if err != nil || code != "8FVC9G8F+6X" {
+		t.Fatalf("Encode() = %q %v, want 8FVC9G8F+6X", code, err)
+	}
+
+	area, err := Decode(code)
+	if err != nil {
+		t.Fatalf("Decode(%q) failed: %v", code, err)
+	}
+	lat, lng := area.Center()
+	if math.Abs(lat-47.3655625) > 1e-9 || math.Abs(lng-8.5249375) > 1e-9 {
+		t.Errorf("Decode(%q).Center() = %v,%v", code, lat, lng)
+	}
+}
+
+func FuzzIsValid(f *testing.F) {
+	for _, seed := range []string{"8FVC9G8F+6X", "8FVC0000+", "22222222+00", "9G8F+6X", "+", "0+0"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, code string) {
+		if IsValid(code) {
+			if _, err := Decode(code); err != nil && IsFull(code) {
+				t.Errorf("Decode(%q) failed on a valid full code: %v", code, err)
+			}
+		}
+		IsShort(code)
+	})
+}
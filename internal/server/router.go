@@ -28,8 +28,15 @@ func InitRouter(app *handlers.App) error {
 	router := http.NewServeMux()
 	router.HandleFunc("GET /health", app.GetHealth)
 	router.HandleFunc("POST /convertUrl", app.PostConvertUrl)
+	router.HandleFunc("POST /convertUrl/batch", app.PostConvertUrlBatch)
+	router.HandleFunc("POST /convertWazeUrl", app.PostConvertWazeUrl)
 	router.HandleFunc("GET /staticMap", app.GetStaticMap)
 	router.HandleFunc("GET /placeDetails", app.GetPlaceDetails)
+	router.HandleFunc("GET /coordinates/convert", app.GetConvertCoordinates)
+
+	admin := middleware.AdminKey(app.Service.Config.AdminAPIKey)
+	router.Handle("GET /admin/places", admin(http.HandlerFunc(app.GetAdminPlaces)))
+	router.Handle("PUT /admin/places/{cid}", admin(http.HandlerFunc(app.PutAdminPlace)))
 
 	stack := middleware.CreateStack(middleware.Logging)
 
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"maps-to-waze-api/handlers"
@@ -12,6 +13,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -46,6 +48,11 @@ func main() {
 	}
 
 	service := services.NewService(db, httpClient, config)
+
+	// Expired cache entries are deleted in the background
+	cleanupCtx, cancelCleanup := context.WithCancel(context.Background())
+	defer cancelCleanup()
+	go service.CleanupCaches(cleanupCtx, config.CacheCleanupEvery)
 	app := &handlers.App{
 		Service: service,
 	}
@@ -89,9 +96,177 @@ func loadConfig() (models.Config, error) {
 		return models.Config{}, fmt.Errorf("MAPS_API_KEY is required")
 	}
 
+	placesApi := os.Getenv("GOOGLE_PLACES_API")
+	if placesApi == "" {
+		placesApi = services.PlacesApiLegacy
+	}
+	if placesApi != services.PlacesApiLegacy && placesApi != services.PlacesApiNew {
+		return models.Config{}, fmt.Errorf("GOOGLE_PLACES_API must be %q or %q", services.PlacesApiLegacy, services.PlacesApiNew)
+	}
+
+	placesNewMonthLimit, err := loadIntConfig("PLACES_NEW_MAX_REQUESTS_PER_MONTH", 10000)
+	if err != nil {
+		return models.Config{}, err
+	}
+
+	placesNewDayLimit, err := loadIntConfig("PLACES_NEW_MAX_REQUESTS_PER_DAY", 500)
+	if err != nil {
+		return models.Config{}, err
+	}
+
+	placesNewLegacyCidFallback := false
+	if fallbackStr := os.Getenv("PLACES_NEW_LEGACY_CID_FALLBACK"); fallbackStr != "" {
+		placesNewLegacyCidFallback, err = strconv.ParseBool(fallbackStr)
+		if err != nil {
+			return models.Config{}, fmt.Errorf("PLACES_NEW_LEGACY_CID_FALLBACK must be a boolean")
+		}
+	}
+
+	extractors, err := loadExtractorsConfig()
+	if err != nil {
+		return models.Config{}, err
+	}
+
+	batchMaxUrls, err := loadIntConfig("CONVERT_URL_BATCH_MAX_URLS", 50)
+	if err != nil {
+		return models.Config{}, err
+	}
+
+	batchWorkers, err := loadIntConfig("CONVERT_URL_BATCH_WORKERS", 4)
+	if err != nil {
+		return models.Config{}, err
+	}
+
+	batchTimeoutSeconds, err := loadIntConfig("CONVERT_URL_BATCH_TIMEOUT_SECONDS", 12)
+	if err != nil {
+		return models.Config{}, err
+	}
+
+	shortLinkTTLHours, err := loadIntConfig("SHORT_LINK_TTL_HOURS", 720)
+	if err != nil {
+		return models.Config{}, err
+	}
+
+	cacheCleanupMinutes, err := loadIntConfig("CACHE_CLEANUP_INTERVAL_MINUTES", 60)
+	if err != nil {
+		return models.Config{}, err
+	}
+
+	// 0 disables the memory tier, conversions are then only cached in Postgres
+	conversionCacheSize, err := loadNonNegativeIntConfig("CONVERSION_CACHE_SIZE", 1000)
+	if err != nil {
+		return models.Config{}, err
+	}
+
+	conversionCacheMemoryTTLMinutes, err := loadIntConfig("CONVERSION_CACHE_MEMORY_TTL_MINUTES", 60)
+	if err != nil {
+		return models.Config{}, err
+	}
+
+	conversionCacheDatabaseTTLHours, err := loadIntConfig("CONVERSION_CACHE_DATABASE_TTL_HOURS", 168)
+	if err != nil {
+		return models.Config{}, err
+	}
+
+	conversionCacheNegativeTTLMinutes, err := loadIntConfig("CONVERSION_CACHE_NEGATIVE_TTL_MINUTES", 10)
+	if err != nil {
+		return models.Config{}, err
+	}
+
+	placeMaxAgeDays, err := loadIntConfig("PLACE_MAX_AGE_DAYS", 90)
+	if err != nil {
+		return models.Config{}, err
+	}
+
 	return models.Config{
 		MapsMaxRequestsPerMonth: mapsMonthLimit,
 		MapsMaxRequestsPerDay:   mapsDayLimit,
 		MapsAPIKey:              mapsAPIKey,
+		Extractors:              extractors,
+		BatchMaxUrls:            batchMaxUrls,
+		BatchWorkers:            batchWorkers,
+		BatchTimeout:            time.Duration(batchTimeoutSeconds) * time.Second,
+		ShortLinkTTL:            time.Duration(shortLinkTTLHours) * time.Hour,
+		CacheCleanupEvery:       time.Duration(cacheCleanupMinutes) * time.Minute,
+
+		ConversionCacheSize:        conversionCacheSize,
+		ConversionCacheMemoryTTL:   time.Duration(conversionCacheMemoryTTLMinutes) * time.Minute,
+		ConversionCacheDatabaseTTL: time.Duration(conversionCacheDatabaseTTLHours) * time.Hour,
+		ConversionCacheNegativeTTL: time.Duration(conversionCacheNegativeTTLMinutes) * time.Minute,
+
+		PlacesApi:                    placesApi,
+		PlacesNewMaxRequestsPerMonth: placesNewMonthLimit,
+		PlacesNewMaxRequestsPerDay:   placesNewDayLimit,
+		PlacesNewLegacyCidFallback:   placesNewLegacyCidFallback,
+
+		PlaceMaxAge: time.Duration(placeMaxAgeDays) * 24 * time.Hour,
+		AdminAPIKey: os.Getenv("ADMIN_API_KEY"),
 	}, nil
 }
+
+// loadIntConfig reads an optional positive integer, defaultValue is used
+// when the variable is not set
+func loadIntConfig(key string, defaultValue int) (int, error) {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue, nil
+	}
+
+	value, err := strconv.Atoi(valueStr)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer", key)
+	}
+
+	return value, nil
+}
+
+// loadNonNegativeIntConfig is loadIntConfig for the settings where 0 turns
+// the feature off
+func loadNonNegativeIntConfig(key string, defaultValue int) (int, error) {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue, nil
+	}
+
+	value, err := strconv.Atoi(valueStr)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("%s must be zero or a positive integer", key)
+	}
+
+	return value, nil
+}
+
+func loadExtractorsConfig() ([]models.ExtractorConfig, error) {
+	order := services.DefaultExtractorOrder
+	if orderStr := os.Getenv("CONVERT_URL_EXTRACTORS"); orderStr != "" {
+		order = strings.Split(orderStr, ",")
+	}
+
+	var extractors []models.ExtractorConfig
+	seen := make(map[string]bool)
+	for _, name := range order {
+		name = strings.TrimSpace(name)
+		if !services.IsKnownExtractor(name) {
+			return nil, fmt.Errorf("CONVERT_URL_EXTRACTORS contains an unknown extractor: %s", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("CONVERT_URL_EXTRACTORS lists the %s extractor twice", name)
+		}
+		seen[name] = true
+
+		// Extractors are enabled, optional ones disabled, unless EXTRACTOR_<NAME>_ENABLED says otherwise
+		enabled := services.IsExtractorEnabledByDefault(name)
+		enabledKey := fmt.Sprintf("EXTRACTOR_%s_ENABLED", strings.ToUpper(name))
+		if enabledStr := os.Getenv(enabledKey); enabledStr != "" {
+			var err error
+			enabled, err = strconv.ParseBool(enabledStr)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be a boolean", enabledKey)
+			}
+		}
+
+		extractors = append(extractors, models.ExtractorConfig{Name: name, Enabled: enabled})
+	}
+
+	return extractors, nil
+}
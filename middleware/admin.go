@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+)
+
+// AdminKey only lets through requests carrying key in the X-Admin-Key
+// header, every request is refused when key is empty
+func AdminKey(key string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provided := r.Header.Get("X-Admin-Key")
+			if key == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(key)) != 1 {
+				slog.WarnContext(r.Context(), "refused admin request", "url", r.URL.Path)
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
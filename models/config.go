@@ -1,7 +1,36 @@
 package models
 
+import "time"
+
 type Config struct {
 	MapsMaxRequestsPerMonth int
 	MapsMaxRequestsPerDay   int
 	MapsAPIKey              string
+	Extractors              []ExtractorConfig
+	BatchMaxUrls            int
+	BatchWorkers            int
+	BatchTimeout            time.Duration
+	ShortLinkTTL            time.Duration
+	CacheCleanupEvery       time.Duration
+
+	ConversionCacheSize        int
+	ConversionCacheMemoryTTL   time.Duration
+	ConversionCacheDatabaseTTL time.Duration
+	ConversionCacheNegativeTTL time.Duration
+
+	// Google Places API used for lookups, "legacy" or "new"
+	PlacesApi                    string
+	PlacesNewMaxRequestsPerMonth int
+	PlacesNewMaxRequestsPerDay   int
+	// Places known only by CID can't be looked up with the new API, they
+	// fail unless the legacy API may be used for them
+	PlacesNewLegacyCidFallback bool
+
+	PlaceMaxAge time.Duration
+	AdminAPIKey string
+}
+
+type ExtractorConfig struct {
+	Name    string
+	Enabled bool
 }
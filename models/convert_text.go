@@ -0,0 +1,12 @@
+package models
+
+type TextMatch struct {
+	Input  string              `json:"input"`
+	Result *ConvertUrlResponse `json:"result,omitempty"`
+	Error  string              `json:"error,omitempty"`
+}
+
+type TextHints struct {
+	Name         string   `json:"name,omitempty"`
+	AddressLines []string `json:"address_lines,omitempty"`
+}
@@ -0,0 +1,15 @@
+package models
+
+type ConvertUrlBatchRequest struct {
+	URLs []string `json:"urls"`
+}
+
+type ConvertUrlBatchResponse struct {
+	Results []ConvertUrlBatchItem `json:"results"`
+}
+
+type ConvertUrlBatchItem struct {
+	URL    string              `json:"url"`
+	Result *ConvertUrlResponse `json:"result,omitempty"`
+	Error  string              `json:"error,omitempty"`
+}
@@ -0,0 +1,8 @@
+package models
+
+type ConvertUrlDebug struct {
+	RedirectChain []string `json:"redirect_chain"`
+	ResolvedURL   string   `json:"resolved_url"`
+	UrlKind       string   `json:"url_kind"`
+	CacheTier     string   `json:"cache_tier,omitempty"`
+}
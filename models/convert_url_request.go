@@ -2,5 +2,7 @@ package models
 
 type ConvertUrlRequest struct {
 	URL string `json:"url"`
+	// Free text with one or more links or coordinates, used instead of URL
+	Text string `json:"text"`
 }
 
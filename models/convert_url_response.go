@@ -1,6 +1,21 @@
 package models
 
 type ConvertUrlResponse struct {
-	URL         string             `json:"url"`
-	Coordinates Coordinates `json:"coordinates"`
+	URL          string             `json:"url"`
+	Coordinates  Coordinates        `json:"coordinates"`
+	Source       string             `json:"source"`
+	Precision    string             `json:"precision"`
+	Uncertainty  *float64           `json:"uncertainty,omitempty"`
+	Provider     string             `json:"provider"`
+	CanonicalURL string             `json:"canonical_url,omitempty"`
+	Name         string             `json:"name,omitempty"`
+	Query        string             `json:"query,omitempty"`
+	PlusCode     string             `json:"plus_code"`
+	Links        map[string]string  `json:"links"`
+	Legs         []DirectionsLeg    `json:"legs,omitempty"`
+	ListEntries  []PlaceListEntry   `json:"list_entries,omitempty"`
+	Alternates   []GeocodeAlternate `json:"alternates,omitempty"`
+	Matches      []TextMatch        `json:"matches,omitempty"`
+	Hints        *TextHints         `json:"hints,omitempty"`
+	Debug        *ConvertUrlDebug   `json:"debug,omitempty"`
 }
@@ -0,0 +1,7 @@
+package models
+
+type ConvertWazeUrlResponse struct {
+	GoogleMapsURL string      `json:"google_maps_url"`
+	AppleMapsURL  string      `json:"apple_maps_url"`
+	Coordinates   Coordinates `json:"coordinates"`
+}
@@ -0,0 +1,11 @@
+package models
+
+type CoordinatesConvertResponse struct {
+	InputFormat string      `json:"input_format"`
+	Coordinates Coordinates `json:"coordinates"`
+	DD          string      `json:"dd"`
+	DMS         string      `json:"dms"`
+	DDM         string      `json:"ddm"`
+	UTM         string      `json:"utm,omitempty"`
+	MGRS        string      `json:"mgrs,omitempty"`
+}
@@ -0,0 +1,10 @@
+package models
+
+type DirectionsLeg struct {
+	Origin      string      `json:"origin"`
+	Destination string      `json:"destination"`
+	Cid         string      `json:"cid,omitempty"`
+	Coordinates Coordinates `json:"coordinates"`
+	URL         string      `json:"url"`
+	Error       string      `json:"error,omitempty"`
+}
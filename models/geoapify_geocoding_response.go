@@ -0,0 +1,20 @@
+package models
+
+type GeoapifyGeocodingResponse struct {
+	Results []GGResult `json:"results"`
+}
+
+type GGResult struct {
+	Lat          float64 `json:"lat"`
+	Lon          float64 `json:"lon"`
+	Name         *string `json:"name"`
+	Formatted    *string `json:"formatted"`
+	AddressLine1 *string `json:"address_line1"`
+	AddressLine2 *string `json:"address_line2"`
+	ResultType   string  `json:"result_type"`
+	Rank         GGRank  `json:"rank"`
+}
+
+type GGRank struct {
+	Confidence float64 `json:"confidence"`
+}
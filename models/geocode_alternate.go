@@ -0,0 +1,8 @@
+package models
+
+type GeocodeAlternate struct {
+	Formatted   string      `json:"formatted"`
+	Coordinates Coordinates `json:"coordinates"`
+	Confidence  float64     `json:"confidence"`
+	URL         string      `json:"url"`
+}
@@ -0,0 +1,17 @@
+package models
+
+type GooglePlacesNewResponse struct {
+	Id          string                `json:"id"`
+	DisplayName GooglePlacesNewText   `json:"displayName"`
+	Location    GooglePlacesNewLatLng `json:"location"`
+}
+
+type GooglePlacesNewText struct {
+	Text         string `json:"text"`
+	LanguageCode string `json:"languageCode"`
+}
+
+type GooglePlacesNewLatLng struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type Place struct {
+	Cid          string    `json:"cid"`
+	Ftid         string    `json:"ftid,omitempty"`
+	Name         string    `json:"name,omitempty"`
+	Latitude     float64   `json:"latitude"`
+	Longitude    float64   `json:"longitude"`
+	FirstSeen    time.Time `json:"first_seen"`
+	LastVerified time.Time `json:"last_verified"`
+}
+
+type PlaceUpdateRequest struct {
+	Ftid      *string  `json:"ftid"`
+	Name      *string  `json:"name"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+}
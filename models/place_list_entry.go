@@ -0,0 +1,9 @@
+package models
+
+type PlaceListEntry struct {
+	Name        string      `json:"name"`
+	Cid         string      `json:"cid,omitempty"`
+	Coordinates Coordinates `json:"coordinates"`
+	URL         string      `json:"url"`
+	Error       string      `json:"error,omitempty"`
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Parameters added by share sheets and analytics, they never change the
+// place a link points to
+var trackingParams = map[string]bool{
+	"g_st":     true,
+	"g_ep":     true,
+	"entry":    true,
+	"shorturl": true,
+	"hl":       true,
+	"gl":       true,
+	"ved":      true,
+	"ei":       true,
+	"sa":       true,
+	"si":       true,
+	"coh":      true,
+	"skid":     true,
+	"authuser": true,
+	"fbclid":   true,
+	"gclid":    true,
+	"_imcp":    true,
+}
+
+// google.de, www.google.co.uk and maps.google.it all serve the same maps
+var googleMapsHostPattern = regexp.MustCompile(`^(www\.|maps\.)?google\.(com|[a-z]{2}|com?\.[a-z]{2})$`)
+
+const canonicalGoogleHost = "www.google.com"
+
+// normalizeUrl returns a copy of parsedUrl with a lowercase host, Google
+// Maps hosts unified to www.google.com/maps, no tracking parameters and the
+// remaining parameters sorted
+func normalizeUrl(parsedUrl *url.URL) *url.URL {
+	normalized := *parsedUrl
+	normalized.User = nil
+	normalized.Scheme = strings.ToLower(normalized.Scheme)
+	normalized.Host = strings.TrimSuffix(strings.ToLower(normalized.Host), ".")
+
+	// Drop the default ports
+	if port := normalized.Port(); (normalized.Scheme == "https" && port == "443") || (normalized.Scheme == "http" && port == "80") {
+		normalized.Host = normalized.Hostname()
+	}
+
+	if match := googleMapsHostPattern.FindStringSubmatch(normalized.Host); match != nil {
+		normalized.Scheme = "https"
+		normalized.Host = canonicalGoogleHost
+		// maps.google.com/?q=… is www.google.com/maps?q=…
+		if match[1] == "maps." && !strings.HasPrefix(normalized.Path, "/maps") {
+			normalized.Path = "/maps" + strings.TrimSuffix(normalized.Path, "/")
+			normalized.RawPath = ""
+		}
+	}
+
+	query := normalized.Query()
+	for key := range query {
+		if trackingParams[strings.ToLower(key)] || strings.HasPrefix(strings.ToLower(key), "utm_") {
+			query.Del(key)
+		}
+	}
+	// Encode sorts the parameters by key
+	normalized.RawQuery = query.Encode()
+	normalized.ForceQuery = false
+
+	return &normalized
+}
+
+// canonicalUrlKey returns the normalized form of Url, so equivalent links
+// share cache entries
+func canonicalUrlKey(Url string) string {
+	parsedUrl, err := url.Parse(strings.TrimSpace(Url))
+	if err != nil {
+		return strings.TrimSpace(Url)
+	}
+
+	return normalizeUrl(parsedUrl).String()
+}
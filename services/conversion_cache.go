@@ -0,0 +1,211 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps-to-waze-api/internal/database"
+	"maps-to-waze-api/models"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Conversions are cached in memory and in Postgres. URLs that hold no
+// location are cached too, for a shorter time, so they don't hit the paid
+// APIs on every retry. Failed lookups are never cached.
+
+const (
+	CacheTierMemory   = "memory"
+	CacheTierDatabase = "database"
+)
+
+var errNoCoordinatesFound = errors.New("ConvertUrl failed: no coordinates, place or search text found in the URL")
+
+type cachedConversion struct {
+	Key      string
+	Response models.ConvertUrlResponse
+	Error    string
+	// Places of the registry the response depends on, a correction evicts it
+	Cids      []string
+	ExpiresAt time.Time
+}
+
+// conversionCache is a fixed size LRU, the least recently used entry is
+// evicted first
+type conversionCache struct {
+	mutex    sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+}
+
+func newConversionCache(capacity int) *conversionCache {
+	return &conversionCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *conversionCache) Get(key string) (cachedConversion, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, found := c.entries[key]
+	if !found {
+		return cachedConversion{}, false
+	}
+
+	entry := element.Value.(cachedConversion)
+	if time.Now().After(entry.ExpiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return cachedConversion{}, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry, true
+}
+
+func (c *conversionCache) Set(entry cachedConversion) {
+	if c.capacity <= 0 {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, found := c.entries[entry.Key]; found {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[entry.Key] = c.order.PushFront(entry)
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(cachedConversion).Key)
+	}
+}
+
+// DeleteCid removes the entries that depend on the place
+func (c *conversionCache) DeleteCid(cid string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for key, element := range c.entries {
+		if slices.Contains(element.Value.(cachedConversion).Cids, cid) {
+			c.order.Remove(element)
+			delete(c.entries, key)
+		}
+	}
+}
+
+// getCachedConversion looks the key up in memory first, then in Postgres.
+// Database hits are copied to memory.
+func (s *Service) getCachedConversion(ctx context.Context, key string) (cachedConversion, string, bool) {
+	if entry, found := s.resultCache.Get(key); found {
+		return entry, CacheTierMemory, true
+	}
+
+	responseJson, errorMessage, cids, expiresAt, found, err := database.GetConversion(ctx, s.DB, key)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to read the conversion cache", "error", err)
+		return cachedConversion{}, "", false
+	}
+	if !found {
+		return cachedConversion{}, "", false
+	}
+
+	entry := cachedConversion{Key: key, Error: errorMessage, Cids: cids, ExpiresAt: expiresAt}
+	if errorMessage == "" {
+		if err := json.Unmarshal(responseJson, &entry.Response); err != nil {
+			slog.WarnContext(ctx, "failed to decode the cached conversion", "error", err)
+			return cachedConversion{}, "", false
+		}
+	}
+
+	memoryExpiresAt := time.Now().Add(s.Config.ConversionCacheMemoryTTL)
+	if memoryExpiresAt.Before(entry.ExpiresAt) {
+		entry.ExpiresAt = memoryExpiresAt
+	}
+	s.resultCache.Set(entry)
+
+	return entry, CacheTierDatabase, true
+}
+
+// cacheConversion stores a converted response, or the error when convertErr
+// means the URL holds no location
+func (s *Service) cacheConversion(ctx context.Context, key string, response models.ConvertUrlResponse, convertErr error) {
+	memoryTTL, databaseTTL := s.Config.ConversionCacheMemoryTTL, s.Config.ConversionCacheDatabaseTTL
+
+	entry := cachedConversion{Key: key, Response: response, Cids: getConversionCids(response)}
+	if convertErr != nil {
+		if !errors.Is(convertErr, errNoCoordinatesFound) {
+			return
+		}
+		entry = cachedConversion{Key: key, Error: convertErr.Error()}
+		memoryTTL, databaseTTL = s.Config.ConversionCacheNegativeTTL, s.Config.ConversionCacheNegativeTTL
+	}
+
+	entry.ExpiresAt = time.Now().Add(memoryTTL)
+	s.resultCache.Set(entry)
+
+	var responseJson []byte
+	if entry.Error == "" {
+		var err error
+		responseJson, err = json.Marshal(response)
+		if err != nil {
+			slog.WarnContext(ctx, fmt.Sprintf("failed to encode the conversion: %v", err))
+			return
+		}
+	}
+
+	if err := database.UpsertConversion(ctx, s.DB, key, string(responseJson), entry.Error, entry.Cids, databaseTTL); err != nil {
+		slog.WarnContext(ctx, "failed to write the conversion cache", "error", err)
+	}
+}
+
+// evictPlaceConversions removes the cached conversions that used the place,
+// so a correction is served right away
+func (s *Service) evictPlaceConversions(ctx context.Context, cid string) error {
+	s.resultCache.DeleteCid(cid)
+
+	evicted, err := database.DeleteConversionsByCid(ctx, s.DB, cid)
+	if err != nil {
+		return fmt.Errorf("failed to evict the conversions of place %s: %w", cid, err)
+	}
+
+	slog.InfoContext(ctx, fmt.Sprintf("evicted %d cached conversions of place %s", evicted, cid))
+	return nil
+}
+
+// getConversionCids returns the CIDs of the places a response depends on:
+// the place of the link, the stops of a route and the places of a list
+func getConversionCids(response models.ConvertUrlResponse) []string {
+	var cids []string
+	if Url, err := url.QueryUnescape(response.CanonicalURL); err == nil {
+		if cid, err := getPlaceIdFromUrl(Url); err == nil && cid != "" {
+			cids = append(cids, cid)
+		}
+	}
+	for _, leg := range response.Legs {
+		if leg.Cid != "" {
+			cids = append(cids, leg.Cid)
+		}
+	}
+	for _, entry := range response.ListEntries {
+		if entry.Cid != "" {
+			cids = append(cids, entry.Cid)
+		}
+	}
+
+	slices.Sort(cids)
+	return slices.Compact(cids)
+}
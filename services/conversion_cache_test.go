@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps-to-waze-api/models"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestConvertUrlCachesOnlyDefinitiveResults(t *testing.T) {
+	const pageUrl = "https://www.google.com/maps/search/pizza"
+	chain := recordedChain{Url: pageUrl, Hops: []recordedHop{{Url: pageUrl, Status: http.StatusOK}}}
+
+	tests := []struct {
+		name       string
+		extractors []Extractor
+		cached     bool
+	}{
+		{
+			name:       "nothing in the URL",
+			extractors: []Extractor{stubExtractor{err: fmt.Errorf("%w: no search text", errNoMatch)}},
+			cached:     true,
+		},
+		{
+			name:       "quota exhausted",
+			extractors: []Extractor{stubExtractor{err: errors.New("exceeded the number of requests today")}},
+		},
+		{
+			name: "viewport after a failed lookup",
+			extractors: []Extractor{
+				stubExtractor{err: errors.New("received non-OK HTTP status: 503 Service Unavailable")},
+				stubExtractor{candidates: []CoordinateCandidate{candidate("45.46", "9.18", SourceViewport)}},
+			},
+		},
+		{
+			name:       "pin",
+			extractors: []Extractor{stubExtractor{candidates: []CoordinateCandidate{candidate("45.4642", "9.19", SourcePin)}}},
+			cached:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newTestService(t)
+			s.RedirectClient = replayChain(t, chain)
+			s.Extractors = test.extractors
+			s.Config.ConversionCacheMemoryTTL = time.Hour
+			s.Config.ConversionCacheNegativeTTL = time.Hour
+
+			s.ConvertUrl(context.Background(), pageUrl, nil)
+
+			if _, cached := s.resultCache.Get(canonicalUrlKey(pageUrl)); cached != test.cached {
+				t.Errorf("cached = %v, want %v", cached, test.cached)
+			}
+		})
+	}
+}
+
+func TestGetConversionCids(t *testing.T) {
+	response := models.ConvertUrlResponse{
+		CanonicalURL: "https://www.google.com/maps/place/Duomo/data=!4m2!3m1!1s0x4786c6aec34636a1:0xab7f4e27101a2e13",
+		Legs:         []models.DirectionsLeg{{Cid: "42"}, {}},
+		ListEntries:  []models.PlaceListEntry{{Cid: "7"}, {Cid: "42"}},
+	}
+
+	cids := getConversionCids(response)
+	if !slices.Equal(cids, []string{"12357681832208772627", "42", "7"}) {
+		t.Errorf("getConversionCids() = %v", cids)
+	}
+}
+
+func TestConversionCacheDeleteCid(t *testing.T) {
+	cache := newConversionCache(4)
+	expiresAt := time.Now().Add(time.Hour)
+	cache.Set(cachedConversion{Key: "duomo", Cids: []string{"42"}, ExpiresAt: expiresAt})
+	cache.Set(cachedConversion{Key: "route", Cids: []string{"7", "42"}, ExpiresAt: expiresAt})
+	cache.Set(cachedConversion{Key: "castello", Cids: []string{"7"}, ExpiresAt: expiresAt})
+
+	cache.DeleteCid("42")
+
+	for key, want := range map[string]bool{"duomo": false, "route": false, "castello": true} {
+		if _, found := cache.Get(key); found != want {
+			t.Errorf("Get(%q) found = %v, want %v", key, found, want)
+		}
+	}
+}
+
+func TestConversionCacheDisabled(t *testing.T) {
+	cache := newConversionCache(0)
+	cache.Set(cachedConversion{Key: "duomo", ExpiresAt: time.Now().Add(time.Hour)})
+
+	if _, found := cache.Get("duomo"); found {
+		t.Errorf("Get() found an entry in a cache of size 0")
+	}
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps-to-waze-api/models"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	textUrlPattern         = regexp.MustCompile(`(?i)https?://[^\s<>"]+`)
+	textGeoUriPattern      = regexp.MustCompile(`(?i)geo:[^\s<>"']+`)
+	textDecimalPairPattern = regexp.MustCompile(`[-+]?\d{1,2}\.\d+\s*,\s*[-+]?\d{1,3}\.\d+`)
+)
+
+// ConvertText converts every map link, geo URI, plus code and coordinate
+// pair found in free text, like the one produced by the Google Maps share
+// sheet. The other lines are returned as hints about the place.
+func (s *Service) ConvertText(ctx context.Context, text string, targets []string) (models.ConvertUrlResponse, error) {
+	if err := validateLinkTargets(targets); err != nil {
+		return models.ConvertUrlResponse{}, err
+	}
+
+	inputs, hints := splitShareText(text)
+	slog.InfoContext(ctx, fmt.Sprintf("found %d convertible inputs in the text", len(inputs)))
+	if len(inputs) == 0 {
+		return models.ConvertUrlResponse{}, fmt.Errorf("ConvertText failed: no map link or coordinates found in the text")
+	}
+
+	var response *models.ConvertUrlResponse
+	var matches []models.TextMatch
+
+	for _, input := range inputs {
+		match := models.TextMatch{Input: input}
+
+		result, err := s.ConvertUrl(ctx, input, targets)
+		if err != nil {
+			slog.WarnContext(ctx, fmt.Sprintf("failed to convert %s: %v", input, err))
+			match.Error = err.Error()
+		} else {
+			match.Result = &result
+			if response == nil {
+				response = &result
+			}
+		}
+
+		matches = append(matches, match)
+	}
+
+	if response == nil {
+		return models.ConvertUrlResponse{}, fmt.Errorf("ConvertText failed: none of the %d inputs could be converted", len(inputs))
+	}
+
+	// The first converted input is the answer, all of them are listed
+	converted := *response
+	converted.Matches = matches
+	if hints.Name != "" || len(hints.AddressLines) > 0 {
+		converted.Hints = &hints
+	}
+
+	return converted, nil
+}
+
+// isShareText reports whether the input holds more than a single link or
+// pair of coordinates, punctuation around a lone link doesn't count
+func isShareText(input string) bool {
+	inputs, hints := splitShareText(input)
+	if len(inputs) == 0 {
+		return false
+	}
+	if len(inputs) > 1 || hints.Name != "" {
+		return true
+	}
+
+	around := strings.Replace(input, inputs[0], "", 1)
+	return strings.IndexFunc(around, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0
+}
+
+// splitShareText returns the convertible inputs in the order they appear and
+// the remaining lines, the first one being the place name
+func splitShareText(text string) ([]string, models.TextHints) {
+	var inputs []string
+	var hints models.TextHints
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		found := false
+		for _, pattern := range []*regexp.Regexp{textUrlPattern, textGeoUriPattern} {
+			for _, match := range pattern.FindAllString(line, -1) {
+				match = trimTextUrl(match)
+				if pattern == textUrlPattern && detectProvider(match) == ProviderUnknown {
+					continue
+				}
+				inputs = append(inputs, match)
+				found = true
+			}
+		}
+		if found {
+			continue
+		}
+
+		// A whole line of coordinates in any format, or a pair inside the line
+		if _, err := parseCoordinatesText(line); err == nil {
+			inputs = append(inputs, line)
+			continue
+		}
+		if match := textDecimalPairPattern.FindString(line); match != "" {
+			inputs = append(inputs, match)
+			continue
+		}
+		if code, found := findGlobalPlusCode(line); found {
+			inputs = append(inputs, code)
+			continue
+		}
+
+		if hints.Name == "" {
+			hints.Name = line
+		} else {
+			hints.AddressLines = append(hints.AddressLines, line)
+		}
+	}
+
+	return inputs, hints
+}
+
+// trimTextUrl drops the punctuation and quotes ending the sentence around a
+// link, apostrophes inside it are kept as in /maps/place/Mario's
+func trimTextUrl(match string) string {
+	for {
+		trimmed := strings.TrimRight(match, ".,;:!?'\"’”")
+		if !strings.Contains(trimmed, "(") {
+			trimmed = strings.TrimRight(trimmed, ")")
+		}
+		if trimmed == match {
+			return match
+		}
+		match = trimmed
+	}
+}
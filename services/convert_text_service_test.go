@@ -0,0 +1,104 @@
+package services
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitShareText(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		inputs       []string
+		placeName    string
+		addressLines []string
+	}{
+		{
+			name:   "apostrophe in the place name",
+			text:   "https://www.google.com/maps/place/Mario's/@45.4642,9.19,17z",
+			inputs: []string{"https://www.google.com/maps/place/Mario's/@45.4642,9.19,17z"},
+		},
+		{
+			name:   "trailing punctuation",
+			text:   "Meet me here: https://maps.app.goo.gl/2Fq8Lq4sTQ5jxx1X8.",
+			inputs: []string{"https://maps.app.goo.gl/2Fq8Lq4sTQ5jxx1X8"},
+		},
+		{
+			name:   "quoted link",
+			text:   "'https://www.google.com/maps/place/Mario's/@45.4642,9.19,17z'!",
+			inputs: []string{"https://www.google.com/maps/place/Mario's/@45.4642,9.19,17z"},
+		},
+		{
+			name:   "closing parenthesis",
+			text:   "the restaurant (https://maps.apple.com/?ll=45.4642,9.19).",
+			inputs: []string{"https://maps.apple.com/?ll=45.4642,9.19"},
+		},
+		{
+			name:   "parenthesis inside the link",
+			text:   "https://www.google.com/maps/place/Duomo+(Milano)/@45.4642,9.19,17z",
+			inputs: []string{"https://www.google.com/maps/place/Duomo+(Milano)/@45.4642,9.19,17z"},
+		},
+		{
+			name: "several links",
+			text: "from https://maps.apple.com/?ll=45.4642,9.19, to https://www.openstreetmap.org/?mlat=45.47&mlon=9.18",
+			inputs: []string{
+				"https://maps.apple.com/?ll=45.4642,9.19",
+				"https://www.openstreetmap.org/?mlat=45.47&mlon=9.18",
+			},
+		},
+		{
+			name:         "share sheet",
+			text:         "Mario's Pizzeria\nVia Roma 1, 20121 Milano\n\nhttps://maps.app.goo.gl/2Fq8Lq4sTQ5jxx1X8",
+			inputs:       []string{"https://maps.app.goo.gl/2Fq8Lq4sTQ5jxx1X8"},
+			placeName:    "Mario's Pizzeria",
+			addressLines: []string{"Via Roma 1, 20121 Milano"},
+		},
+		{
+			name:         "link of another site",
+			text:         "Mario's\nhttps://example.com/mario's",
+			placeName:    "Mario's",
+			addressLines: []string{"https://example.com/mario's"},
+		},
+		{
+			name:   "coordinates inside a sentence",
+			text:   "I'm at 45.4642, 9.19 now",
+			inputs: []string{"45.4642, 9.19"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inputs, hints := splitShareText(test.text)
+			if !slices.Equal(inputs, test.inputs) {
+				t.Errorf("inputs = %q, want %q", inputs, test.inputs)
+			}
+			if hints.Name != test.placeName || !slices.Equal(hints.AddressLines, test.addressLines) {
+				t.Errorf("hints = %+v, want %q %q", hints, test.placeName, test.addressLines)
+			}
+		})
+	}
+}
+
+func TestIsShareText(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"https://www.google.com/maps/place/Mario's/@45.4642,9.19,17z", false},
+		{"  https://www.google.com/maps/place/Mario's/@45.4642,9.19,17z  ", false},
+		{"https://maps.app.goo.gl/2Fq8Lq4sTQ5jxx1X8.", false},
+		{"'https://maps.app.goo.gl/2Fq8Lq4sTQ5jxx1X8'", false},
+		{"45.4642, 9.19", false},
+		{"Duomo di Milano", false},
+		{"Mario's https://www.google.com/maps/place/Mario's/@45.4642,9.19,17z", true},
+		{"https://maps.app.goo.gl/2Fq8Lq4sTQ5jxx1X8 see you there", true},
+		{"Mario's\nhttps://maps.app.goo.gl/2Fq8Lq4sTQ5jxx1X8", true},
+		{"https://maps.apple.com/?ll=45.4642,9.19 https://maps.apple.com/?ll=45.47,9.18", true},
+	}
+
+	for _, test := range tests {
+		if got := isShareText(test.input); got != test.want {
+			t.Errorf("isShareText(%q) = %v, want %v", test.input, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps-to-waze-api/models"
+	"sync"
+)
+
+// ConvertUrlBatch converts the URLs with a bounded pool of workers, the
+// results keep the input order and the whole batch shares one deadline
+func (s *Service) ConvertUrlBatch(ctx context.Context, urls []string, targets []string) (models.ConvertUrlBatchResponse, error) {
+	if len(urls) == 0 {
+		return models.ConvertUrlBatchResponse{}, fmt.Errorf("ConvertUrlBatch failed: no URLs in the request")
+	}
+
+	if len(urls) > s.Config.BatchMaxUrls {
+		return models.ConvertUrlBatchResponse{}, fmt.Errorf("ConvertUrlBatch failed: %d URLs exceed the limit of %d", len(urls), s.Config.BatchMaxUrls)
+	}
+
+	if err := validateLinkTargets(targets); err != nil {
+		return models.ConvertUrlBatchResponse{}, err
+	}
+
+	slog.InfoContext(ctx, fmt.Sprintf("converting a batch of %d URLs", len(urls)))
+
+	ctx, cancel := context.WithTimeout(ctx, s.Config.BatchTimeout)
+	defer cancel()
+
+	results := make([]models.ConvertUrlBatchItem, len(urls))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(s.Config.BatchWorkers, len(urls)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = s.convertBatchItem(ctx, urls[i], targets)
+			}
+		}()
+	}
+
+	for i := range urls {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return models.ConvertUrlBatchResponse{Results: results}, nil
+}
+
+func (s *Service) convertBatchItem(ctx context.Context, Url string, targets []string) models.ConvertUrlBatchItem {
+	item := models.ConvertUrlBatchItem{URL: Url}
+
+	// Items still queued when the deadline expires are not started
+	if err := ctx.Err(); err != nil {
+		item.Error = fmt.Sprintf("ConvertUrlBatch deadline exceeded: %v", err)
+		return item
+	}
+
+	result, err := s.ConvertUrl(ctx, Url, targets)
+	if err != nil {
+		slog.WarnContext(ctx, fmt.Sprintf("failed to convert %s: %v", Url, err))
+		item.Error = err.Error()
+		return item
+	}
+
+	item.Result = &result
+	return item
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -18,133 +19,466 @@ import (
 var (
 	urlSearchPattern = regexp.MustCompile(`([-+]?\d{1,2}\.\d+),\s*([-+]?\d{1,3}\.\d+)`)
 	urlAtPattern     = regexp.MustCompile(`@(-?\d+\.\d+),(-?\d+\.\d+)`)
+	urlPairPattern   = regexp.MustCompile(`^\s*([-+]?\d{1,2}(?:\.\d+)?)\s*[,~_]\s*([-+]?\d{1,3}(?:\.\d+)?)`)
+	osmMapPattern    = regexp.MustCompile(`map=\d+(?:\.\d+)?/([-+]?\d+(?:\.\d+)?)/([-+]?\d+(?:\.\d+)?)`)
 	placeFtidPattern = regexp.MustCompile(`ftid.*:(\w+)`)
 	placeDataPattern = regexp.MustCompile(`data=.*0x(\w+)`)
 	placeHexPattern  = regexp.MustCompile(`:0x(\w+)`)
 )
 
-func (s *Service) ConvertUrl(ctx context.Context, Url string) (models.ConvertUrlResponse, error) {
-	// Step 1: Follow the redirect to get the decompressed google maps Url
+// CIDs are unsigned 64 bit integers
+var decimalCidPattern = regexp.MustCompile(`^\d{1,20}$`)
+
+const (
+	ProviderGoogle        = "google_maps"
+	ProviderApple         = "apple_maps"
+	ProviderOpenStreetMap = "openstreetmap"
+	ProviderBing          = "bing_maps"
+	ProviderHere          = "here_wego"
+	ProviderUnknown       = "unknown"
+)
+
+func (s *Service) ConvertUrl(ctx context.Context, Url string, targets []string) (models.ConvertUrlResponse, error) {
+	if err := validateLinkTargets(targets); err != nil {
+		return models.ConvertUrlResponse{}, err
+	}
+
+	// Share text forwarded as is, with the links somewhere inside
+	if isShareText(Url) {
+		slog.InfoContext(ctx, "the input is share text")
+		return s.ConvertText(ctx, Url, targets)
+	}
+
+	// Step 1: Inputs that are not web links are parsed locally
+	if !isHttpUrl(Url) {
+		slog.InfoContext(ctx, "parsing the input locally")
+		candidate, provider, err := parseLocalInput(Url)
+
+		// Short plus codes need their locality to be geocoded
+		if code, locality, found := findShortPlusCode(Url); err != nil && found {
+			candidate, err = s.resolveShortPlusCode(ctx, code, locality)
+			provider = ProviderPlusCode
+		}
+
+		if err != nil {
+			slog.WarnContext(ctx, "ConvertUrl failed to parse the input", "error", err)
+			return models.ConvertUrlResponse{}, fmt.Errorf("ConvertUrl failed to parse the input: %w", err)
+		}
+
+		return newConvertUrlResponse(ctx, candidate, provider, targets), nil
+	}
+
+	// Equivalent links share the result of the first conversion
+	cacheKey := canonicalUrlKey(Url)
+	if entry, tier, found := s.getCachedConversion(ctx, cacheKey); found {
+		slog.InfoContext(ctx, fmt.Sprintf("conversion found in the cache: %s", cacheKey), "tier", tier)
+		if entry.Error != "" {
+			return models.ConvertUrlResponse{}, errors.New(entry.Error)
+		}
+		return newCachedConvertUrlResponse(entry.Response, tier, targets), nil
+	}
+
+	// Results degraded by a failed lookup are not cached
+	response, complete, err := s.convertHttpUrl(ctx, Url, targets)
+	if complete {
+		s.cacheConversion(ctx, cacheKey, response, err)
+	}
+
+	return response, err
+}
+
+// convertHttpUrl also tells whether every lookup the conversion needed
+// succeeded, failed ones may work on a retry
+func (s *Service) convertHttpUrl(ctx context.Context, Url string, targets []string) (models.ConvertUrlResponse, bool, error) {
+	// Step 2: Follow the redirect to get the decompressed google maps Url
 	slog.InfoContext(ctx, fmt.Sprintf("obtaining redirect URL from %s", Url))
-	redirectUrl, err := s.getRedirectUrl(ctx, Url)
+	resolution, err := s.resolveUrl(ctx, Url)
 	if err != nil {
-		slog.ErrorContext(ctx, "ConvertUrl failed to get redirect URL", "error", err)
-		return models.ConvertUrlResponse{}, fmt.Errorf("ConvertUrl failed to get redirect URL: %w ", err)
+		slog.ErrorContext(ctx, "ConvertUrl failed to get redirect URL", "error", err, "redirect_chain", resolution.Chain)
+		return models.ConvertUrlResponse{}, false, fmt.Errorf("ConvertUrl failed to get redirect URL: %w ", err)
 	}
+	redirectUrl := resolution.Url
 	slog.DebugContext(ctx, fmt.Sprintf("redirect URL: %s", redirectUrl))
 
-	// Step 2: Try to get the coordinates parsing the Url
-	slog.InfoContext(ctx, "trying to get the coordinates from the URL")
-	coordinates, err := getCoordinatesFromUrl(redirectUrl)
-	if err != nil {
-		slog.WarnContext(ctx, fmt.Sprintf("convertUrl failed to get coordinates from URL: %v ", err))
+	slog.InfoContext(ctx, fmt.Sprintf("canonical URL: %s", resolution.CanonicalUrl))
+
+	debug := &models.ConvertUrlDebug{
+		RedirectChain: resolution.Chain,
+		ResolvedURL:   redirectUrl,
+		UrlKind:       classifyUrl(redirectUrl),
 	}
-	if coordinates.Latitude != "" && coordinates.Longitude != "" {
-		slog.InfoContext(ctx, fmt.Sprintf("coordinates found: %v", coordinates))
-		url := getWazeLinkFromCoordinates(coordinates)
-		return models.ConvertUrlResponse{URL: url, Coordinates: coordinates}, nil
+	slog.InfoContext(ctx, "resolved the URL", "redirect_chain", debug.RedirectChain, "url_kind", debug.UrlKind)
+
+	provider := detectProvider(redirectUrl)
+	slog.InfoContext(ctx, "detected map provider", "provider", provider)
+
+	// Step 3: Routes are converted stop by stop
+	if isDirectionsUrl(redirectUrl) {
+		slog.InfoContext(ctx, "converting the directions URL")
+		response, err := s.convertDirectionsUrl(ctx, redirectUrl, provider, targets)
+		if err != nil {
+			return models.ConvertUrlResponse{}, false, err
+		}
+		response.CanonicalURL = resolution.CanonicalUrl
+		response.Debug = debug
+		return response, !hasLegErrors(response.Legs), nil
 	}
 
-	// Step 3: Try to get the coordinates from the Google Maps API
-	slog.InfoContext(ctx, "trying to get the coordinates from the Google Maps API")
-	coordinates, err = s.getCoordinatesFromApi(ctx, redirectUrl)
-	if err != nil {
-		slog.WarnContext(ctx, fmt.Sprintf("convertUrl failed to get coordinates from API: %v ", err))
+	// Shared lists are converted place by place
+	if debug.UrlKind == UrlKindList {
+		slog.InfoContext(ctx, "converting the shared list")
+		response, err := s.convertPlaceList(ctx, redirectUrl, provider, targets)
+		if err != nil {
+			return models.ConvertUrlResponse{}, false, err
+		}
+		response.CanonicalURL = resolution.CanonicalUrl
+		response.Debug = debug
+		return response, !hasListEntryErrors(response.ListEntries), nil
 	}
-	if coordinates.Latitude != "" && coordinates.Longitude != "" {
-		slog.InfoContext(ctx, fmt.Sprintf("coordinates found: %v", coordinates))
-		url := getWazeLinkFromCoordinates(coordinates)
-		return models.ConvertUrlResponse{URL: url, Coordinates: coordinates}, nil
+
+	// Step 4: Run the extractors until one of them finds the exact position
+	candidates, extractErr := s.runExtractors(ctx, redirectUrl)
+	if best, found := bestCandidate(candidates); found {
+		response := newConvertUrlResponse(ctx, best, provider, targets)
+		response.CanonicalURL = resolution.CanonicalUrl
+		response.Name, response.Query = getPlaceTextFromUrl(resolution.CanonicalUrl)
+		response.Debug = debug
+		return response, extractErr == nil, nil
 	}
 
-	// Step 4: If no coordinates were found, return an error
+	// Step 5: If no coordinates were found, return an error
+	if extractErr != nil {
+		slog.WarnContext(ctx, "no coordinates found, some lookups failed", "error", extractErr)
+		return models.ConvertUrlResponse{}, false, fmt.Errorf("ConvertUrl failed to look up the coordinates: %w", extractErr)
+	}
 	slog.WarnContext(ctx, "no coordinates found")
-	return models.ConvertUrlResponse{}, fmt.Errorf("ConvertUrl failed")
+	return models.ConvertUrlResponse{}, true, errNoCoordinatesFound
 }
 
-func getWazeLinkFromCoordinates(coordinates models.Coordinates) string {
-	return fmt.Sprintf("https://www.waze.com/ul?ll=%s,%s&navigate=yes", coordinates.Latitude, coordinates.Longitude)
+func newConvertUrlResponse(ctx context.Context, candidate CoordinateCandidate, provider string, targets []string) models.ConvertUrlResponse {
+	slog.InfoContext(ctx, fmt.Sprintf("coordinates found: %v", candidate.Coordinates), "source", candidate.Source)
+
+	return models.ConvertUrlResponse{
+		URL:         getWazeLinkFromCoordinates(candidate.Coordinates),
+		Coordinates: candidate.Coordinates,
+		Source:      string(candidate.Source),
+		Precision:   candidate.Precision(),
+		Uncertainty: candidate.Uncertainty,
+		Provider:    provider,
+		PlusCode:    getPlusCodeFromCoordinates(candidate.Coordinates),
+		Links:       getNavigationLinks(candidate.Coordinates, targets),
+		Alternates:  candidate.Alternates,
+	}
+}
+
+// newCachedConvertUrlResponse rebuilds the links of a cached response for
+// the requested targets
+func newCachedConvertUrlResponse(response models.ConvertUrlResponse, tier string, targets []string) models.ConvertUrlResponse {
+	response.Links = getNavigationLinks(response.Coordinates, targets)
+
+	debug := models.ConvertUrlDebug{}
+	if response.Debug != nil {
+		debug = *response.Debug
+	}
+	debug.CacheTier = tier
+	response.Debug = &debug
+
+	return response
 }
 
 func (s *Service) getRedirectUrl(ctx context.Context, Url string) (string, error) {
+	resolution, err := s.resolveUrl(ctx, Url)
+	if err != nil {
+		return "", err
+	}
+
+	return resolution.Url, nil
+}
+
+type urlResolution struct {
+	// Decoded URL of the last page
+	Url string
+	// Normalized URL of the last page, still encoded
+	CanonicalUrl string
+	// Every URL visited, starting with the input
+	Chain []string
+}
+
+// resolveUrl follows the redirects of Url. The chain is also filled when
+// resolving fails, up to the hop that failed.
+func (s *Service) resolveUrl(ctx context.Context, Url string) (urlResolution, error) {
+	resolution := urlResolution{Chain: []string{Url}}
+
+	// Tracking parameters don't change where a link redirects
+	inputUrl := canonicalUrlKey(Url)
+
+	// Short links always point to the same page, resolve them once
+	isShortLink := false
+	if parsedUrl, err := url.Parse(inputUrl); err == nil && isShortLinkHost(parsedUrl.Hostname()) {
+		isShortLink = true
+		if resolvedUrl, found := s.getCachedShortLink(ctx, inputUrl); found {
+			decodedUrl, err := url.QueryUnescape(resolvedUrl)
+			if err == nil {
+				resolution.Url = decodedUrl
+				resolution.CanonicalUrl = resolvedUrl
+				resolution.Chain = append(resolution.Chain, resolvedUrl)
+				return resolution, nil
+			}
+		}
+	}
+
 	// Create a context-aware request
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, Url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, inputUrl, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request to %s: %w", Url, err)
+		return resolution, fmt.Errorf("failed to create request to %s: %w", Url, err)
 	}
 
-	// Use the injected client
-	resp, err := s.HTTPClient.Do(req)
+	// The redirects are checked by the client, the first URL here
+	if err := validateRedirectUrl(req.URL); err != nil {
+		return resolution, fmt.Errorf("refused to resolve %s: %w", Url, err)
+	}
+
+	resp, err := s.RedirectClient.Do(req)
+	if resp != nil {
+		resolution.Chain = getRedirectChain(resp)
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to make the request to %s: %w", Url, err)
+		return resolution, fmt.Errorf("failed to make the request to %s: %w", Url, err)
 	}
+	// Only the final URL is needed, the body is never read
 	defer resp.Body.Close()
 
 	if resp.Request == nil {
-		return "", fmt.Errorf("failed to obtain the redirect URL from response")
+		return resolution, fmt.Errorf("failed to obtain the redirect URL from response")
 	}
 
-	redirectUrl := resp.Request.URL
-	decodedUrl, err := url.QueryUnescape(redirectUrl.String())
+	// Consent and captcha pages hide the real URL in a parameter
+	redirectUrl, err := unwrapInterstitial(resp.Request.URL)
+	if err != nil {
+		return resolution, err
+	}
+	if redirectUrl != resp.Request.URL {
+		resolution.Chain = append(resolution.Chain, redirectUrl.String())
+	}
+
+	// Keep the fragment like a browser would, OpenStreetMap puts the map position there
+	if redirectUrl.Fragment == "" {
+		redirectUrl.Fragment = req.URL.Fragment
+	}
+	canonicalUrl := normalizeUrl(redirectUrl).String()
+	decodedUrl, err := url.QueryUnescape(canonicalUrl)
 
 	if err != nil {
-		return "", fmt.Errorf("failed to decode the redirect URL: %w", err)
+		return resolution, fmt.Errorf("failed to decode the redirect URL: %w", err)
 	}
 
-	return decodedUrl, nil
+	resolution.Url = decodedUrl
+	resolution.CanonicalUrl = canonicalUrl
+	if isShortLink {
+		s.cacheShortLink(ctx, inputUrl, canonicalUrl)
+	}
+
+	return resolution, nil
 }
 
-func getCoordinatesFromUrl(Url string) (models.Coordinates, error) {
-	var pattern *regexp.Regexp
+// getRedirectChain walks back from the last response to the first request.
+// When a redirect was refused the response is the one that asked for it, so
+// the refused location is added at the end.
+func getRedirectChain(resp *http.Response) []string {
+	var chain []string
+	for req := resp.Request; req != nil; {
+		chain = append([]string{req.URL.String()}, chain...)
+		if req.Response == nil {
+			break
+		}
+		req = req.Response.Request
+	}
 
-	// Decide which regex pattern to use based on the Url
-	if strings.Contains(Url, "search/") || strings.Contains(Url, "place") {
-		pattern = urlSearchPattern
-	} else {
-		pattern = urlAtPattern
+	if location := resp.Header.Get("Location"); location != "" && resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		if locationUrl, err := resp.Request.URL.Parse(location); err == nil {
+			chain = append(chain, locationUrl.String())
+		}
 	}
 
-	// Find matching coordinates in the Url
-	match := pattern.FindStringSubmatch(Url)
-	if match == nil {
-		return models.Coordinates{}, fmt.Errorf("failed to match the coordinates in the URL")
+	return chain
+}
+
+func detectProvider(Url string) string {
+	parsedUrl, err := url.Parse(Url)
+	if err != nil {
+		return ProviderUnknown
+	}
+	host := strings.ToLower(parsedUrl.Hostname())
+
+	switch {
+	case host == "goo.gl" || strings.HasSuffix(host, ".goo.gl") ||
+		strings.HasPrefix(host, "google.") || strings.Contains(host, ".google."):
+		return ProviderGoogle
+	case host == "maps.apple.com" || host == "maps.apple":
+		return ProviderApple
+	case host == "osm.org" || host == "openstreetmap.org" || strings.HasSuffix(host, ".openstreetmap.org"):
+		return ProviderOpenStreetMap
+	case (host == "bing.com" || strings.HasSuffix(host, ".bing.com")) && strings.HasPrefix(parsedUrl.Path, "/maps"):
+		return ProviderBing
+	case host == "here.com" || strings.HasSuffix(host, ".here.com"):
+		return ProviderHere
+	default:
+		return ProviderUnknown
 	}
+}
 
-	// Extract latitude and longitude
-	latitude, longitude := match[1], match[2]
+func getCoordinatesFromUrl(Url string) ([]CoordinateCandidate, error) {
+	var candidates []CoordinateCandidate
 
-	if latitude == "" || longitude == "" {
-		return models.Coordinates{}, fmt.Errorf("failed to get the latitude and longitude from the URL")
+	// Coordinates typed by the user in the search box or passed as query
+	if coordinates, found := getQueryCoordinatesFromUrl(Url); found {
+		candidates = append(candidates, CoordinateCandidate{Coordinates: coordinates, Source: SourceQuery})
 	}
 
-	return models.Coordinates{Latitude: latitude, Longitude: longitude}, nil
+	// The @ is the center of the map, which can be far from the place
+	if match := urlAtPattern.FindStringSubmatch(Url); match != nil {
+		candidates = append(candidates, CoordinateCandidate{
+			Coordinates: models.Coordinates{Latitude: match[1], Longitude: match[2]},
+			Source:      SourceViewport,
+		})
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("failed to match the coordinates in the URL")
+	}
+
+	return candidates, nil
+}
+
+func getQueryCoordinatesFromUrl(Url string) (models.Coordinates, bool) {
+	var texts []string
+
+	parsedUrl, err := url.Parse(Url)
+	if err == nil {
+		for _, param := range []string{"q", "query", "daddr", "destination"} {
+			texts = append(texts, parsedUrl.Query().Get(param))
+		}
+	}
+
+	// Path segments following /search/ or /place/
+	segments := strings.Split(Url, "/")
+	for i, segment := range segments[:len(segments)-1] {
+		if segment == "search" || segment == "place" {
+			texts = append(texts, segments[i+1])
+		}
+	}
+
+	for _, text := range texts {
+		match := urlSearchPattern.FindStringSubmatch(text)
+		if match != nil && !strings.HasPrefix(text, "@") && isValidCoordinates(match[1], match[2]) {
+			return models.Coordinates{Latitude: match[1], Longitude: match[2]}, true
+		}
+	}
+
+	return models.Coordinates{}, false
+}
+
+// getPlaceTextFromUrl returns the place name of /place/<name>/ links and
+// the searched text of /search/<query>/ links, the name falls back to the
+// one stored in the data parameter
+func getPlaceTextFromUrl(Url string) (string, string) {
+	var name, query string
+
+	parsedUrl, err := url.Parse(Url)
+	if err != nil {
+		return "", ""
+	}
+
+	segments := strings.Split(parsedUrl.EscapedPath(), "/")
+	for i, segment := range segments[:len(segments)-1] {
+		if segment != "place" && segment != "search" {
+			continue
+		}
+
+		text, err := url.QueryUnescape(segments[i+1])
+		text = strings.TrimSpace(text)
+		if err != nil || text == "" || strings.HasPrefix(text, "@") {
+			continue
+		}
+		if _, err := parseCoordinatesText(text); err == nil {
+			continue
+		}
+
+		if segment == "place" && name == "" {
+			name = text
+		}
+		if segment == "search" && query == "" {
+			query = text
+		}
+	}
+
+	if name == "" {
+		if data, err := parseDataParamFromUrl(Url); err == nil {
+			name, _ = url.QueryUnescape(data.Place().Name)
+		}
+	}
+
+	return name, query
 }
 
 func (s *Service) getCoordinatesFromApi(ctx context.Context, Url string) (models.Coordinates, error) {
+	// Get the place ID from the Url
+	placeID, err := getPlaceIdFromUrl(Url)
+	if err != nil || placeID == "" {
+		return models.Coordinates{}, fmt.Errorf("%w: failed to extract place ID from URL", errNoMatch)
+	}
+
+	hint := models.Place{Cid: placeID}
+	if dataParam, err := parseDataParamFromUrl(Url); err == nil && dataParam.Place().Cid == placeID {
+		place := dataParam.Place()
+		hint.Ftid, hint.Name = place.Ftid, place.Name
+	} else if ftid, err := ftidFromPlaceId(findGooglePlaceId(Url)); err == nil {
+		hint.Ftid = ftid
+	}
+
+	return s.lookupPlace(ctx, hint)
+}
+
+func (s *Service) reservePlacesRequest(ctx context.Context, requestTypeId int, monthLimit int, dayLimit int) error {
+	s.quotaMutex.Lock()
+	defer s.quotaMutex.Unlock()
+
 	// Check that the number of requests this month is below the limit
-	canProcede, err := s.checkNumberOfRequestsThisMonth(ctx, MapsPlacesRequestTypeId, nil, s.Config.MapsMaxRequestsPerMonth)
+	canProcede, err := s.checkNumberOfRequestsThisMonth(ctx, requestTypeId, nil, monthLimit)
 	if err != nil {
-		return models.Coordinates{}, fmt.Errorf("failed to check the number of requests this month: %w", err)
+		return fmt.Errorf("failed to check the number of requests this month: %w", err)
 	}
 
 	if !canProcede {
-		return models.Coordinates{}, fmt.Errorf("exceeded the number of requests this month")
+		return fmt.Errorf("exceeded the number of requests this month")
 	}
 
 	// Check that the number of requests today is below the limit
-	canProcede, err = s.checkNumberOfRequestsToday(ctx, MapsPlacesRequestTypeId, nil, s.Config.MapsMaxRequestsPerDay)
+	canProcede, err = s.checkNumberOfRequestsToday(ctx, requestTypeId, nil, dayLimit)
 	if err != nil {
-		return models.Coordinates{}, fmt.Errorf("failed to check the number of requests today: %w", err)
+		return fmt.Errorf("failed to check the number of requests today: %w", err)
 	}
 
 	if !canProcede {
-		return models.Coordinates{}, fmt.Errorf("exceeded the number of requests today")
+		return fmt.Errorf("exceeded the number of requests today")
 	}
 
-	// Get the place ID from the Url
-	placeID, err := getPlaceIdFromUrl(Url)
-	if err != nil || placeID == "" {
-		return models.Coordinates{}, fmt.Errorf("failed to extract place ID from URL")
+	// Track the request in the database
+	requestId := ctx.Value("request_id").(string)
+	err = database.InsertRequest(ctx, s.DB, requestId, requestTypeId)
+	if err != nil {
+		return fmt.Errorf("failed to insert the request in the database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) getCoordinatesFromCid(ctx context.Context, placeID string) (models.Coordinates, error) {
+	// Check the limits and track the request before calling the API, so
+	// concurrent conversions can't go over the quota together
+	err := s.reservePlacesRequest(ctx, MapsPlacesRequestTypeId, s.Config.MapsMaxRequestsPerMonth, s.Config.MapsMaxRequestsPerDay)
+	if err != nil {
+		return models.Coordinates{}, err
 	}
 
 	// Call google maps api and get the coordinates
@@ -167,13 +501,6 @@ func (s *Service) getCoordinatesFromApi(ctx context.Context, Url string) (models
 	}
 	defer resp.Body.Close()
 
-	// Track the request in the database
-	requestId := ctx.Value("request_id").(string)
-	err = database.InsertRequest(ctx, s.DB, requestId, MapsPlacesRequestTypeId)
-	if err != nil {
-		return models.Coordinates{}, fmt.Errorf("failed to insert the request in the database: %w", err)
-	}
-
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
 		return models.Coordinates{}, fmt.Errorf("received non-OK HTTP status: %s", resp.Status)
@@ -191,6 +518,11 @@ func (s *Service) getCoordinatesFromApi(ctx context.Context, Url string) (models
 		return models.Coordinates{}, fmt.Errorf("failed to unmarshal the response: %w", err)
 	}
 
+	// The CID doesn't exist, asking again won't help
+	if placeResp.Status == "NOT_FOUND" || placeResp.Status == "ZERO_RESULTS" {
+		return models.Coordinates{}, fmt.Errorf("%w: received %s status from API", errNoMatch, placeResp.Status)
+	}
+
 	if placeResp.Status != "OK" {
 		return models.Coordinates{}, fmt.Errorf("received non-OK status from API: %s", placeResp.Status)
 	}
@@ -203,6 +535,25 @@ func (s *Service) getCoordinatesFromApi(ctx context.Context, Url string) (models
 }
 
 func getPlaceIdFromUrl(Url string) (string, error) {
+	// cid= links already carry the decimal CID
+	if parsedUrl, err := url.Parse(Url); err == nil {
+		if cid := parsedUrl.Query().Get("cid"); decimalCidPattern.MatchString(cid) {
+			return cid, nil
+		}
+	}
+
+	dataParam, err := parseDataParamFromUrl(Url)
+	if err == nil {
+		if place := dataParam.Place(); place.Cid != "" {
+			return place.Cid, nil
+		}
+	}
+
+	// Place IDs of api=1 links hold the ftid
+	if ftid, err := ftidFromPlaceId(findGooglePlaceId(Url)); err == nil {
+		return cidFromFtid(ftid)
+	}
+
 	patterns := []*regexp.Regexp{
 		placeFtidPattern,
 		placeDataPattern,
@@ -228,3 +579,145 @@ func getPlaceIdFromUrl(Url string) (string, error) {
 
 	return "", nil
 }
+
+func parseCoordinatePair(text string) (models.Coordinates, bool) {
+	match := urlPairPattern.FindStringSubmatch(text)
+	if match == nil {
+		return models.Coordinates{}, false
+	}
+
+	return normalizeCoordinates(match[1], match[2])
+}
+
+func getCoordinatesFromAppleMapsUrl(Url string) ([]CoordinateCandidate, error) {
+	parsedUrl, err := url.Parse(Url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the Apple Maps URL: %w", err)
+	}
+	query := parsedUrl.Query()
+
+	var candidates []CoordinateCandidate
+
+	// ll (or coordinate on the newer links) is where the pin is dropped
+	for _, param := range []string{"ll", "coordinate"} {
+		if coordinates, found := parseCoordinatePair(query.Get(param)); found {
+			candidates = append(candidates, CoordinateCandidate{Coordinates: coordinates, Source: SourcePin})
+		}
+	}
+
+	// q and address are usually labels, unless coordinates were searched
+	for _, param := range []string{"q", "address"} {
+		if coordinates, found := parseCoordinatePair(query.Get(param)); found {
+			candidates = append(candidates, CoordinateCandidate{Coordinates: coordinates, Source: SourceQuery})
+		}
+	}
+
+	for _, param := range []string{"center", "sll"} {
+		if coordinates, found := parseCoordinatePair(query.Get(param)); found {
+			candidates = append(candidates, CoordinateCandidate{Coordinates: coordinates, Source: SourceViewport})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("failed to match the coordinates in the Apple Maps URL")
+	}
+
+	return candidates, nil
+}
+
+func getCoordinatesFromOpenStreetMapUrl(Url string) ([]CoordinateCandidate, error) {
+	parsedUrl, err := url.Parse(Url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the OpenStreetMap URL: %w", err)
+	}
+	query := parsedUrl.Query()
+
+	var candidates []CoordinateCandidate
+
+	// mlat and mlon place a marker on the map
+	if mlat, mlon := query.Get("mlat"), query.Get("mlon"); isValidCoordinates(mlat, mlon) {
+		candidates = append(candidates, CoordinateCandidate{
+			Coordinates: models.Coordinates{Latitude: mlat, Longitude: mlon},
+			Source:      SourcePin,
+		})
+	}
+
+	// #map=zoom/lat/lon, or the legacy lat and lon parameters, are the map center
+	if match := osmMapPattern.FindStringSubmatch(parsedUrl.Fragment); match != nil && isValidCoordinates(match[1], match[2]) {
+		candidates = append(candidates, CoordinateCandidate{
+			Coordinates: models.Coordinates{Latitude: match[1], Longitude: match[2]},
+			Source:      SourceViewport,
+		})
+	}
+	if lat, lon := query.Get("lat"), query.Get("lon"); isValidCoordinates(lat, lon) {
+		candidates = append(candidates, CoordinateCandidate{
+			Coordinates: models.Coordinates{Latitude: lat, Longitude: lon},
+			Source:      SourceViewport,
+		})
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("failed to match the coordinates in the OpenStreetMap URL")
+	}
+
+	return candidates, nil
+}
+
+func getCoordinatesFromBingMapsUrl(Url string) ([]CoordinateCandidate, error) {
+	parsedUrl, err := url.Parse(Url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the Bing Maps URL: %w", err)
+	}
+	query := parsedUrl.Query()
+
+	var candidates []CoordinateCandidate
+
+	// sp=point.lat_lon_name is a pushpin
+	for _, point := range strings.Split(query.Get("sp"), "~") {
+		if coordinates, found := parseCoordinatePair(strings.TrimPrefix(point, "point.")); found && strings.HasPrefix(point, "point.") {
+			candidates = append(candidates, CoordinateCandidate{Coordinates: coordinates, Source: SourcePin})
+			break
+		}
+	}
+
+	// cp=lat~lon is the center of the map
+	if coordinates, found := parseCoordinatePair(query.Get("cp")); found {
+		candidates = append(candidates, CoordinateCandidate{Coordinates: coordinates, Source: SourceViewport})
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("failed to match the coordinates in the Bing Maps URL")
+	}
+
+	return candidates, nil
+}
+
+func getCoordinatesFromHereUrl(Url string) ([]CoordinateCandidate, error) {
+	parsedUrl, err := url.Parse(Url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the HERE URL: %w", err)
+	}
+
+	var candidates []CoordinateCandidate
+
+	// share.here.com/l/lat,lon,name and wego.here.com/location/lat,lon
+	segments := strings.Split(strings.Trim(parsedUrl.Path, "/"), "/")
+	for i, segment := range segments[:len(segments)-1] {
+		if segment == "l" || segment == "location" {
+			if coordinates, found := parseCoordinatePair(segments[i+1]); found {
+				candidates = append(candidates, CoordinateCandidate{Coordinates: coordinates, Source: SourcePin})
+			}
+		}
+	}
+
+	// map=lat,lon,zoom is the center of the map
+	if coordinates, found := parseCoordinatePair(parsedUrl.Query().Get("map")); found {
+		candidates = append(candidates, CoordinateCandidate{Coordinates: coordinates, Source: SourceViewport})
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("failed to match the coordinates in the HERE URL")
+	}
+
+	return candidates, nil
+}
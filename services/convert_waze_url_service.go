@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps-to-waze-api/models"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// waze.com/live-map/directions, also behind a locale like /it/ or /pt-BR/
+var wazeLiveMapPattern = regexp.MustCompile(`(?i)^(/[a-z]{2}(-[a-z]{2})?)?/live-map/directions`)
+
+// Venues coming from Google are identified by their place ID, Waze's own
+// venues can't be resolved without the Waze API
+var errWazeVenueNotResolvable = errors.New("the Waze venue can only be resolved by Waze")
+
+// wazeDestination is where a Waze link navigates to, either coordinates or
+// the Google place ID of a venue
+type wazeDestination struct {
+	Coordinates models.Coordinates
+	PlaceId     string
+}
+
+func (s *Service) ConvertWazeUrl(ctx context.Context, Url string) (models.ConvertWazeUrlResponse, error) {
+	// Step 1: Full links are parsed locally
+	destination, err := parseWazeUrl(Url)
+
+	// Step 2: Follow the redirect, waze.com/ul/h… links are shortened
+	if err != nil && !errors.Is(err, errWazeVenueNotResolvable) {
+		slog.InfoContext(ctx, fmt.Sprintf("obtaining redirect URL from %s", Url))
+		redirectUrl, redirectErr := s.getRedirectUrl(ctx, Url)
+		if redirectErr != nil {
+			slog.ErrorContext(ctx, "ConvertWazeUrl failed to get redirect URL", "error", redirectErr)
+			return models.ConvertWazeUrlResponse{}, fmt.Errorf("ConvertWazeUrl failed to get redirect URL: %w ", redirectErr)
+		}
+		slog.DebugContext(ctx, fmt.Sprintf("redirect URL: %s", redirectUrl))
+
+		destination, err = parseWazeUrl(redirectUrl)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, fmt.Sprintf("ConvertWazeUrl failed to get coordinates from URL: %v ", err))
+		return models.ConvertWazeUrlResponse{}, fmt.Errorf("ConvertWazeUrl failed: %w", err)
+	}
+
+	// Step 3: Venues are looked up by their place ID
+	coordinates := destination.Coordinates
+	if destination.PlaceId != "" {
+		coordinates, err = s.getCoordinatesFromWazeVenue(ctx, destination.PlaceId)
+		if err != nil {
+			slog.WarnContext(ctx, "ConvertWazeUrl failed to look up the venue", "error", err)
+			return models.ConvertWazeUrlResponse{}, fmt.Errorf("ConvertWazeUrl failed to look up the venue: %w", err)
+		}
+	}
+	slog.InfoContext(ctx, fmt.Sprintf("coordinates found: %v", coordinates))
+
+	return models.ConvertWazeUrlResponse{
+		GoogleMapsURL: getGoogleMapsLinkFromCoordinates(coordinates),
+		AppleMapsURL:  getAppleMapsLinkFromCoordinates(coordinates),
+		Coordinates:   coordinates,
+	}, nil
+}
+
+func (s *Service) getCoordinatesFromWazeVenue(ctx context.Context, placeId string) (models.Coordinates, error) {
+	ftid, err := ftidFromPlaceId(placeId)
+	if err != nil {
+		return models.Coordinates{}, err
+	}
+
+	cid, err := cidFromFtid(ftid)
+	if err != nil {
+		return models.Coordinates{}, err
+	}
+
+	return s.lookupPlace(ctx, models.Place{Cid: cid, Ftid: ftid})
+}
+
+func parseWazeUrl(Url string) (wazeDestination, error) {
+	parsedUrl, err := url.Parse(strings.TrimSpace(Url))
+	if err != nil {
+		return wazeDestination{}, fmt.Errorf("failed to parse the Waze URL: %w", err)
+	}
+
+	host := strings.ToLower(parsedUrl.Hostname())
+	if host != "waze.com" && !strings.HasSuffix(host, ".waze.com") {
+		return wazeDestination{}, fmt.Errorf("%s is not a Waze URL", host)
+	}
+	query := parsedUrl.Query()
+
+	// waze.com/live-map/directions?to=ll.lat,lon or to=place.<id>
+	if wazeLiveMapPattern.MatchString(parsedUrl.Path) {
+		to := query.Get("to")
+		if coordinates, found := parseCoordinatePair(strings.TrimPrefix(to, "ll.")); found && strings.HasPrefix(to, "ll.") {
+			return wazeDestination{Coordinates: coordinates}, nil
+		}
+		if strings.HasPrefix(to, "place.") {
+			return wazeVenueDestination(strings.TrimPrefix(to, "place."))
+		}
+
+		return wazeDestination{}, fmt.Errorf("failed to match the destination in the Waze directions URL")
+	}
+
+	// waze.com/ul?ll=lat,lon&navigate=yes, q= can hold coordinates too
+	for _, param := range []string{"ll", "q"} {
+		if coordinates, found := parseCoordinatePair(query.Get(param)); found {
+			return wazeDestination{Coordinates: coordinates}, nil
+		}
+	}
+
+	if venueId := query.Get("venue_id"); venueId != "" {
+		return wazeVenueDestination(venueId)
+	}
+
+	return wazeDestination{}, fmt.Errorf("failed to match the coordinates in the Waze URL")
+}
+
+func wazeVenueDestination(venueId string) (wazeDestination, error) {
+	if !strings.HasPrefix(venueId, "ChIJ") {
+		return wazeDestination{}, fmt.Errorf("venue %s: %w", venueId, errWazeVenueNotResolvable)
+	}
+
+	return wazeDestination{PlaceId: venueId}, nil
+}
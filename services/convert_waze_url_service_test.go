@@ -0,0 +1,46 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseWazeUrl(t *testing.T) {
+	tests := []struct {
+		url      string
+		lat, lng string
+		placeId  string
+		err      error
+	}{
+		{url: "https://www.waze.com/ul?ll=45.4642%2C9.19&navigate=yes", lat: "45.4642", lng: "9.19"},
+		{url: "https://waze.com/ul?q=45.4642,9.19", lat: "45.4642", lng: "9.19"},
+		{url: "https://www.waze.com/live-map/directions?to=ll.45.4642%2C9.19", lat: "45.4642", lng: "9.19"},
+		{url: "https://www.waze.com/it/live-map/directions?to=ll.45.4642%2C9.19", lat: "45.4642", lng: "9.19"},
+		{url: "https://www.waze.com/pt-BR/live-map/directions?to=place.ChIJ1234", placeId: "ChIJ1234"},
+		{url: "https://www.waze.com/ul?venue_id=ChIJ1234&navigate=yes", placeId: "ChIJ1234"},
+		{url: "https://www.waze.com/ul?venue_id=123.456.789", err: errWazeVenueNotResolvable},
+	}
+
+	for _, test := range tests {
+		destination, err := parseWazeUrl(test.url)
+		if test.err != nil {
+			if !errors.Is(err, test.err) {
+				t.Errorf("parseWazeUrl(%q) error = %v, want %v", test.url, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseWazeUrl(%q) failed: %v", test.url, err)
+			continue
+		}
+		if destination.Coordinates.Latitude != test.lat || destination.Coordinates.Longitude != test.lng || destination.PlaceId != test.placeId {
+			t.Errorf("parseWazeUrl(%q) = %+v", test.url, destination)
+		}
+	}
+
+	for _, Url := range []string{"https://waze.com/ul/hu0nd7q8mw", "https://www.google.com/maps?ll=45.4642,9.19"} {
+		if _, err := parseWazeUrl(Url); err == nil {
+			t.Errorf("parseWazeUrl(%q) succeeded, the link has to be resolved first", Url)
+		}
+	}
+}
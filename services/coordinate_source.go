@@ -0,0 +1,76 @@
+package services
+
+import "maps-to-waze-api/models"
+
+type CoordinateSource string
+
+// Sources are listed from the most to the least accurate
+const (
+	SourcePin       CoordinateSource = "pin"
+	SourceQuery     CoordinateSource = "query"
+	SourcePlusCode  CoordinateSource = "plus_code"
+	SourcePlacesApi CoordinateSource = "places_api"
+	SourcePage      CoordinateSource = "page"
+	SourceGeocoder  CoordinateSource = "geocoder"
+	SourceViewport  CoordinateSource = "viewport"
+)
+
+const (
+	PrecisionExact       = "exact"
+	PrecisionApproximate = "approximate"
+)
+
+var coordinateSourceRank = map[CoordinateSource]int{
+	SourcePin:       0,
+	SourceQuery:     1,
+	SourcePlusCode:  2,
+	SourcePlacesApi: 3,
+	SourcePage:      4,
+	SourceGeocoder:  5,
+	SourceViewport:  6,
+}
+
+// Past this uncertainty, in meters, a position is not precise enough to
+// drive to
+const approximateUncertaintyMeters = 100
+
+type CoordinateCandidate struct {
+	Coordinates models.Coordinates
+	Source      CoordinateSource
+	// Uncertainty in meters, when the source states it
+	Uncertainty *float64
+	// Set by sources that know their match is loose
+	Approximate bool
+	// Other matches, when the source is a search
+	Alternates []models.GeocodeAlternate
+}
+
+func (c CoordinateCandidate) Precision() string {
+	if c.Source == SourceViewport || c.Approximate {
+		return PrecisionApproximate
+	}
+	if c.Uncertainty != nil && *c.Uncertainty > approximateUncertaintyMeters {
+		return PrecisionApproximate
+	}
+
+	return PrecisionExact
+}
+
+func (c CoordinateCandidate) outranks(source CoordinateSource) bool {
+	return coordinateSourceRank[c.Source] < coordinateSourceRank[source]
+}
+
+func bestCandidate(candidates []CoordinateCandidate) (CoordinateCandidate, bool) {
+	if len(candidates) == 0 {
+		return CoordinateCandidate{}, false
+	}
+
+	best := candidates[0]
+	for _, candidate := range candidates[1:] {
+		if candidate.outranks(best.Source) {
+			best = candidate
+		}
+	}
+
+	return best, true
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps-to-waze-api/internal/coordformat"
+	"maps-to-waze-api/models"
+)
+
+func (s *Service) ConvertCoordinates(ctx context.Context, text string) (models.CoordinatesConvertResponse, error) {
+	slog.InfoContext(ctx, fmt.Sprintf("converting coordinates: %s", text))
+
+	point, format, err := coordformat.Parse(text)
+	if err != nil {
+		return models.CoordinatesConvertResponse{}, err
+	}
+
+	response := models.CoordinatesConvertResponse{
+		InputFormat: string(format),
+		Coordinates: coordinatesFromPoint(point),
+		DD:          coordformat.FormatDecimalDegrees(point),
+		DMS:         coordformat.FormatDegreesMinutesSeconds(point),
+		DDM:         coordformat.FormatDegreesDecimalMinutes(point),
+	}
+
+	// UTM and MGRS do not cover the polar regions
+	if utm, err := coordformat.FormatUTM(point); err == nil {
+		response.UTM = utm
+	}
+	if mgrs, err := coordformat.FormatMGRS(point); err == nil {
+		response.MGRS = mgrs
+	}
+
+	return response, nil
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps-to-waze-api/models"
+	"net/url"
+	"strings"
+)
+
+const currentLocationName = "Current location"
+
+type directionsWaypoint struct {
+	Name        string
+	Ftid        string
+	Coordinates models.Coordinates
+}
+
+func isDirectionsUrl(Url string) bool {
+	if detectProvider(Url) != ProviderGoogle {
+		return false
+	}
+
+	parsedUrl, err := url.Parse(Url)
+	if err != nil {
+		return false
+	}
+
+	return strings.Contains(parsedUrl.Path, "/dir/") || strings.HasSuffix(parsedUrl.Path, "/dir") ||
+		parsedUrl.Query().Get("daddr") != ""
+}
+
+// convertDirectionsUrl turns every stop of a route into its own leg, since
+// Waze navigates to one destination at a time. The response points to the
+// final destination and fails when it can't be resolved.
+func (s *Service) convertDirectionsUrl(ctx context.Context, Url string, provider string, targets []string) (models.ConvertUrlResponse, error) {
+	waypoints, err := getWaypointsFromDirectionsUrl(Url)
+	if err != nil {
+		return models.ConvertUrlResponse{}, err
+	}
+	slog.InfoContext(ctx, fmt.Sprintf("found %d waypoints in the directions URL", len(waypoints)))
+
+	var legs []models.DirectionsLeg
+	var destination CoordinateCandidate
+	var destinationErr error
+
+	for i := 1; i < len(waypoints); i++ {
+		leg := models.DirectionsLeg{
+			Origin:      waypoints[i-1].Name,
+			Destination: waypoints[i].Name,
+		}
+		if waypoints[i].Ftid != "" {
+			leg.Cid, _ = cidFromFtid(waypoints[i].Ftid)
+		}
+
+		candidate, err := s.resolveWaypoint(ctx, waypoints[i])
+		if err != nil {
+			slog.WarnContext(ctx, fmt.Sprintf("failed to resolve waypoint %d: %v", i, err))
+			leg.Error = err.Error()
+		} else {
+			leg.Coordinates = candidate.Coordinates
+			leg.URL = getWazeLinkFromCoordinates(candidate.Coordinates)
+		}
+		if i == len(waypoints)-1 {
+			destination, destinationErr = candidate, err
+		}
+
+		legs = append(legs, leg)
+	}
+
+	// An intermediate stop is not a fallback for the final destination
+	if destinationErr != nil {
+		return models.ConvertUrlResponse{}, fmt.Errorf("ConvertUrl failed to resolve the destination of the directions URL: %w", destinationErr)
+	}
+
+	response := newConvertUrlResponse(ctx, destination, provider, targets)
+	response.Legs = legs
+
+	return response, nil
+}
+
+func hasLegErrors(legs []models.DirectionsLeg) bool {
+	for _, leg := range legs {
+		if leg.Error != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Service) resolveWaypoint(ctx context.Context, waypoint directionsWaypoint) (CoordinateCandidate, error) {
+	if waypoint.Coordinates.Latitude != "" && waypoint.Coordinates.Longitude != "" {
+		return CoordinateCandidate{Coordinates: waypoint.Coordinates, Source: SourcePin}, nil
+	}
+
+	if waypoint.Ftid != "" {
+		cid, err := cidFromFtid(waypoint.Ftid)
+		if err != nil {
+			return CoordinateCandidate{}, err
+		}
+		coordinates, err := s.lookupPlace(ctx, models.Place{Cid: cid, Ftid: waypoint.Ftid, Name: waypoint.Name})
+		if err != nil {
+			return CoordinateCandidate{}, err
+		}
+		return CoordinateCandidate{Coordinates: coordinates, Source: SourcePlacesApi}, nil
+	}
+
+	if waypoint.Name == "" || waypoint.Name == currentLocationName {
+		return CoordinateCandidate{}, fmt.Errorf("the waypoint is the current location")
+	}
+
+	if coordinates, err := parseCoordinatesText(waypoint.Name); err == nil {
+		return CoordinateCandidate{Coordinates: coordinates, Source: SourceQuery}, nil
+	}
+
+	results, err := s.geocodeText(ctx, waypoint.Name, 1, nil)
+	if err != nil {
+		return CoordinateCandidate{}, err
+	}
+
+	return CoordinateCandidate{
+		Coordinates: results[0].Coordinates,
+		Source:      SourceGeocoder,
+		Approximate: results[0].Confidence < geocoderExactConfidence,
+	}, nil
+}
+
+// getWaypointsFromDirectionsUrl reads the stops from the /dir/ path
+// segments, or from the api=1 and saddr/daddr parameters, and completes
+// them with the feature ids and positions stored in the data parameter
+func getWaypointsFromDirectionsUrl(Url string) ([]directionsWaypoint, error) {
+	parsedUrl, err := url.Parse(Url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the directions URL: %w", err)
+	}
+	query := parsedUrl.Query()
+
+	var names []string
+	switch {
+	case query.Get("daddr") != "":
+		names = append(names, query.Get("saddr"))
+		names = append(names, strings.Split(query.Get("daddr"), " to:")...)
+	case query.Get("destination") != "":
+		names = append(names, query.Get("origin"))
+		if waypoints := query.Get("waypoints"); waypoints != "" {
+			names = append(names, strings.Split(waypoints, "|")...)
+		}
+		names = append(names, query.Get("destination"))
+	default:
+		names = getDirectionsPathSegments(Url)
+	}
+
+	waypoints := make([]directionsWaypoint, len(names))
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			name = currentLocationName
+		}
+		waypoints[i].Name = name
+		if coordinates, err := parseCoordinatesText(name); err == nil {
+			waypoints[i].Coordinates = coordinates
+		}
+	}
+
+	// The data parameter lists the same stops in the same order
+	if dataParam, err := parseDataParamFromUrl(Url); err == nil {
+		dataWaypoints := dataParam.DirectionsWaypoints()
+		if len(waypoints) == 0 {
+			waypoints = dataWaypoints
+			for i := range waypoints {
+				if waypoints[i].Name == "" {
+					waypoints[i].Name = fmt.Sprintf("Stop %d", i)
+				}
+			}
+		} else if len(dataWaypoints) == len(waypoints) {
+			for i, dataWaypoint := range dataWaypoints {
+				waypoints[i].Ftid = dataWaypoint.Ftid
+				if dataWaypoint.Coordinates.Latitude != "" {
+					waypoints[i].Coordinates = dataWaypoint.Coordinates
+				}
+			}
+		}
+	}
+
+	if len(waypoints) < 2 {
+		return nil, fmt.Errorf("the directions URL has less than two waypoints")
+	}
+
+	return waypoints, nil
+}
+
+// getDirectionsPathSegments returns what follows /dir/ up to the @ viewport
+// or the data parameter
+func getDirectionsPathSegments(Url string) []string {
+	_, path, found := strings.Cut(Url, "/dir/")
+	if !found {
+		return nil
+	}
+	path, _, _ = strings.Cut(path, "?")
+
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "@") || strings.HasPrefix(segment, "data=") || strings.HasPrefix(segment, "am=") {
+			break
+		}
+		segments = append(segments, segment)
+	}
+
+	// A trailing slash leaves an empty segment that is not a stop
+	if len(segments) > 0 && segments[len(segments)-1] == "" {
+		segments = segments[:len(segments)-1]
+	}
+
+	return segments
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"context"
+	"testing"
+)
+
+func TestConvertDirectionsUrl(t *testing.T) {
+	s := &Service{}
+
+	response, err := s.convertDirectionsUrl(context.Background(),
+		"https://www.google.com/maps/dir/45.4642,9.19/45.4781,9.2256/@45.47,9.2,14z", ProviderGoogle, nil)
+	if err != nil {
+		t.Fatalf("convertDirectionsUrl() failed: %v", err)
+	}
+	if response.Coordinates.Latitude != "45.4781" || response.Coordinates.Longitude != "9.2256" || len(response.Legs) != 1 {
+		t.Errorf("convertDirectionsUrl() = %v with %d legs, want the final stop", response.Coordinates, len(response.Legs))
+	}
+
+	// The final stop is the current location, the first stop is not a fallback
+	_, err = s.convertDirectionsUrl(context.Background(),
+		"https://www.google.com/maps/dir/45.4642,9.19/45.4781,9.2256/Current location/", ProviderGoogle, nil)
+	if err == nil {
+		t.Errorf("convertDirectionsUrl() succeeded without the final destination")
+	}
+}
+
+func TestGetDirectionsPathSegments(t *testing.T) {
+	segments := getDirectionsPathSegments("https://www.google.com/maps/dir/Duomo/Castello/Navigli/@45.46,9.18,13z/data=!4m2")
+	if len(segments) != 3 || segments[0] != "Duomo" || segments[2] != "Navigli" {
+		t.Errorf("getDirectionsPathSegments() = %q", segments)
+	}
+}
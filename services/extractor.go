@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps-to-waze-api/models"
+)
+
+// An Extractor finds candidate coordinates for an already resolved URL.
+// Extractors run in the configured order until one of them yields an exact
+// candidate.
+type Extractor interface {
+	Name() string
+	Extract(ctx context.Context, Url string) ([]CoordinateCandidate, error)
+}
+
+// errNoMatch is wrapped by the errors of extractors that found nothing they
+// understand in the URL. Other errors are lookups that failed and may work
+// on a retry.
+var errNoMatch = errors.New("nothing to extract")
+
+const (
+	DataParamExtractorName  = "data_param"
+	RegexExtractorName      = "regex"
+	CidLookupExtractorName  = "cid_lookup"
+	AppleMapsExtractorName  = "apple_maps"
+	OsmExtractorName        = "openstreetmap"
+	BingMapsExtractorName   = "bing_maps"
+	HereExtractorName       = "here_wego"
+	PlusCodeExtractorName   = "plus_code"
+	GeocoderExtractorName   = "geocoder"
+	PageScrapeExtractorName = "page_scrape"
+)
+
+var extractorRegistry = map[string]func(s *Service) Extractor{
+	DataParamExtractorName: func(s *Service) Extractor { return dataParamExtractor{} },
+	RegexExtractorName:     func(s *Service) Extractor { return regexExtractor{} },
+	CidLookupExtractorName: func(s *Service) Extractor { return cidLookupExtractor{service: s} },
+	AppleMapsExtractorName: func(s *Service) Extractor {
+		return providerExtractor{name: AppleMapsExtractorName, provider: ProviderApple, parse: getCoordinatesFromAppleMapsUrl}
+	},
+	OsmExtractorName: func(s *Service) Extractor {
+		return providerExtractor{name: OsmExtractorName, provider: ProviderOpenStreetMap, parse: getCoordinatesFromOpenStreetMapUrl}
+	},
+	BingMapsExtractorName: func(s *Service) Extractor {
+		return providerExtractor{name: BingMapsExtractorName, provider: ProviderBing, parse: getCoordinatesFromBingMapsUrl}
+	},
+	HereExtractorName: func(s *Service) Extractor {
+		return providerExtractor{name: HereExtractorName, provider: ProviderHere, parse: getCoordinatesFromHereUrl}
+	},
+	PlusCodeExtractorName:   func(s *Service) Extractor { return plusCodeExtractor{service: s} },
+	GeocoderExtractorName:   func(s *Service) Extractor { return geocoderExtractor{service: s} },
+	PageScrapeExtractorName: func(s *Service) Extractor { return pageScrapeExtractor{service: s} },
+}
+
+var DefaultExtractorOrder = []string{
+	DataParamExtractorName,
+	RegexExtractorName,
+	AppleMapsExtractorName,
+	OsmExtractorName,
+	BingMapsExtractorName,
+	HereExtractorName,
+	PlusCodeExtractorName,
+	CidLookupExtractorName,
+	PageScrapeExtractorName,
+	GeocoderExtractorName,
+}
+
+// Extractors that only run when EXTRACTOR_<NAME>_ENABLED is set
+var optionalExtractors = map[string]bool{
+	PageScrapeExtractorName: true,
+}
+
+func IsExtractorEnabledByDefault(name string) bool {
+	return !optionalExtractors[name]
+}
+
+func IsKnownExtractor(name string) bool {
+	_, found := extractorRegistry[name]
+	return found
+}
+
+func (s *Service) buildExtractorChain(configs []models.ExtractorConfig) []Extractor {
+	var chain []Extractor
+	for _, config := range configs {
+		newExtractor, found := extractorRegistry[config.Name]
+		if !found {
+			slog.Warn("skipping unknown extractor", "extractor", config.Name)
+			continue
+		}
+		if !config.Enabled {
+			slog.Info("extractor disabled", "extractor", config.Name)
+			continue
+		}
+
+		chain = append(chain, newExtractor(s))
+	}
+
+	return chain
+}
+
+// runExtractors returns the candidates found, and the errors of the
+// extractors that failed for another reason than finding nothing in the URL
+func (s *Service) runExtractors(ctx context.Context, Url string) ([]CoordinateCandidate, error) {
+	var candidates []CoordinateCandidate
+	var errs []error
+
+	for _, extractor := range s.Extractors {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		slog.InfoContext(ctx, "running extractor", "extractor", extractor.Name())
+		found, err := extractor.Extract(ctx, Url)
+		if errors.Is(err, errNoMatch) {
+			slog.DebugContext(ctx, fmt.Sprintf("extractor failed: %v", err), "extractor", extractor.Name())
+			continue
+		}
+		if err != nil {
+			slog.WarnContext(ctx, fmt.Sprintf("extractor failed: %v", err), "extractor", extractor.Name())
+			errs = append(errs, fmt.Errorf("%s: %w", extractor.Name(), err))
+			continue
+		}
+
+		candidates = append(candidates, found...)
+		if best, ok := bestCandidate(candidates); ok && best.Precision() == PrecisionExact {
+			break
+		}
+	}
+
+	return candidates, errors.Join(errs...)
+}
+
+// dataParamExtractor reads the pin stored in the data= parameter, or the
+// center of an embedded map
+type dataParamExtractor struct{}
+
+func (dataParamExtractor) Name() string {
+	return DataParamExtractorName
+}
+
+func (dataParamExtractor) Extract(ctx context.Context, Url string) ([]CoordinateCandidate, error) {
+	dataParam, err := parseDataParamFromUrl(Url)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errNoMatch, err)
+	}
+
+	place := dataParam.Place()
+	if place.Latitude == "" || place.Longitude == "" {
+		// The embed camera is near the place, the ftid gives the exact position
+		if camera, found := dataParam.EmbedCamera(); found {
+			return []CoordinateCandidate{{Coordinates: camera, Source: SourceViewport}}, nil
+		}
+		return nil, fmt.Errorf("%w: no pin in the data parameter", errNoMatch)
+	}
+
+	return []CoordinateCandidate{{
+		Coordinates: models.Coordinates{Latitude: place.Latitude, Longitude: place.Longitude},
+		Source:      SourcePin,
+	}}, nil
+}
+
+// regexExtractor matches coordinates written in the query or after the @
+type regexExtractor struct{}
+
+func (regexExtractor) Name() string {
+	return RegexExtractorName
+}
+
+func (regexExtractor) Extract(ctx context.Context, Url string) ([]CoordinateCandidate, error) {
+	candidates, err := getCoordinatesFromUrl(Url)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errNoMatch, err)
+	}
+
+	return candidates, nil
+}
+
+// cidLookupExtractor asks the Google Places API for the place in the URL
+type cidLookupExtractor struct {
+	service *Service
+}
+
+func (cidLookupExtractor) Name() string {
+	return CidLookupExtractorName
+}
+
+func (e cidLookupExtractor) Extract(ctx context.Context, Url string) ([]CoordinateCandidate, error) {
+	if provider := detectProvider(Url); provider != ProviderGoogle {
+		return nil, fmt.Errorf("%w: cannot look up a %s URL in Google Places", errNoMatch, provider)
+	}
+
+	coordinates, err := e.service.getCoordinatesFromApi(ctx, Url)
+	if err != nil {
+		return nil, err
+	}
+
+	return []CoordinateCandidate{{Coordinates: coordinates, Source: SourcePlacesApi}}, nil
+}
+
+// providerExtractor parses the links of a non Google map provider
+type providerExtractor struct {
+	name     string
+	provider string
+	parse    func(Url string) ([]CoordinateCandidate, error)
+}
+
+func (e providerExtractor) Name() string {
+	return e.name
+}
+
+func (e providerExtractor) Extract(ctx context.Context, Url string) ([]CoordinateCandidate, error) {
+	if provider := detectProvider(Url); provider != e.provider {
+		return nil, fmt.Errorf("%w: cannot parse a %s URL as %s", errNoMatch, provider, e.provider)
+	}
+
+	candidates, err := e.parse(Url)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errNoMatch, err)
+	}
+
+	return candidates, nil
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps-to-waze-api/models"
+	"testing"
+)
+
+type extractorTest struct {
+	name    string
+	url     string
+	want    []CoordinateCandidate
+	wantErr bool
+}
+
+func runExtractorTests(t *testing.T, extractor Extractor, tests []extractorTest) {
+	t.Helper()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := extractor.Extract(context.Background(), test.url)
+			if test.wantErr {
+				if !errors.Is(err, errNoMatch) {
+					t.Fatalf("Extract(%q) = %v, %v, want no match", test.url, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Extract(%q) failed: %v", test.url, err)
+			}
+
+			if len(got) != len(test.want) {
+				t.Fatalf("Extract(%q) = %v, want %v", test.url, got, test.want)
+			}
+			for i := range got {
+				if got[i].Coordinates != test.want[i].Coordinates || got[i].Source != test.want[i].Source {
+					t.Errorf("candidate %d = %v %s, want %v %s", i,
+						got[i].Coordinates, got[i].Source, test.want[i].Coordinates, test.want[i].Source)
+				}
+			}
+		})
+	}
+}
+
+func candidate(latitude string, longitude string, source CoordinateSource) CoordinateCandidate {
+	return CoordinateCandidate{
+		Coordinates: models.Coordinates{Latitude: latitude, Longitude: longitude},
+		Source:      source,
+	}
+}
+
+// stubExtractor returns the same result for every URL
+type stubExtractor struct {
+	candidates []CoordinateCandidate
+	err        error
+}
+
+func (stubExtractor) Name() string {
+	return "stub"
+}
+
+func (e stubExtractor) Extract(ctx context.Context, Url string) ([]CoordinateCandidate, error) {
+	return e.candidates, e.err
+}
+
+func TestDataParamExtractor(t *testing.T) {
+	runExtractorTests(t, dataParamExtractor{}, []extractorTest{
+		{
+			name: "place pin",
+			url:  "https://www.google.com/maps/place/Duomo/@45.4640,9.1890,17z/data=!3m1!4b1!4m6!3m5!1s0x4786c6aec34636a1:0xab7f4e27101a2e13!8m2!3d45.4641013!4d9.1919265!16s%2Fm%2F01",
+			want: []CoordinateCandidate{candidate("45.4641013", "9.1919265", SourcePin)},
+		},
+		{
+			name: "embed camera",
+			url:  "https://www.google.com/maps/embed?pb=!1m18!1m12!1m3!1d2798.2!2d9.1919!3d45.4641!2m3!1f0!2f0!3f0!3m2!1i1024!2i768!4f13.1!3m3!1m2!1s0x0%3A0x0!2zNDXCsA!5e0!3m2!1sen!2sit!4v1",
+			want: []CoordinateCandidate{candidate("45.4641", "9.1919", SourceViewport)},
+		},
+		{
+			name:    "no data parameter",
+			url:     "https://www.google.com/maps/@45.4640,9.1890,17z",
+			wantErr: true,
+		},
+		{
+			name:    "malformed data parameter",
+			url:     "https://www.google.com/maps/place/X/data=!4m!!3d",
+			wantErr: true,
+		},
+	})
+}
+
+func TestRegexExtractor(t *testing.T) {
+	runExtractorTests(t, regexExtractor{}, []extractorTest{
+		{
+			name: "search coordinates and viewport",
+			url:  "https://www.google.com/maps/search/45.4642, 9.1900/@45.4600,9.1800,15z",
+			want: []CoordinateCandidate{
+				candidate("45.4642", "9.1900", SourceQuery),
+				candidate("45.4600", "9.1800", SourceViewport),
+			},
+		},
+		{
+			name: "query parameter",
+			url:  "https://www.google.com/maps?q=-33.8568,151.2153",
+			want: []CoordinateCandidate{candidate("-33.8568", "151.2153", SourceQuery)},
+		},
+		{
+			name: "viewport only",
+			url:  "https://www.google.com/maps/place/Duomo/@45.4640,9.1890,17z",
+			want: []CoordinateCandidate{candidate("45.4640", "9.1890", SourceViewport)},
+		},
+		{
+			name:    "no coordinates",
+			url:     "https://www.google.com/maps?q=Via+Roma+1+Milano",
+			wantErr: true,
+		},
+	})
+}
+
+func TestAppleMapsExtractor(t *testing.T) {
+	runExtractorTests(t, extractorRegistry[AppleMapsExtractorName](nil), []extractorTest{
+		{
+			name: "pin and center",
+			url:  "https://maps.apple.com/?ll=45.4642,9.19&q=Duomo&sll=45.46,9.18",
+			want: []CoordinateCandidate{
+				candidate("45.4642", "9.19", SourcePin),
+				candidate("45.46", "9.18", SourceViewport),
+			},
+		},
+		{
+			name: "coordinates searched",
+			url:  "https://maps.apple.com/?q=45.4642,9.19",
+			want: []CoordinateCandidate{candidate("45.4642", "9.19", SourceQuery)},
+		},
+		{
+			name:    "label only",
+			url:     "https://maps.apple.com/?address=Piazza+del+Duomo,+Milano",
+			wantErr: true,
+		},
+		{
+			name:    "other provider",
+			url:     "https://www.google.com/maps/@45.4640,9.1890,17z",
+			wantErr: true,
+		},
+	})
+}
+
+func TestOpenStreetMapExtractor(t *testing.T) {
+	runExtractorTests(t, extractorRegistry[OsmExtractorName](nil), []extractorTest{
+		{
+			name: "marker and map center",
+			url:  "https://www.openstreetmap.org/?mlat=45.4642&mlon=9.1900#map=17/45.4640/9.1890",
+			want: []CoordinateCandidate{
+				candidate("45.4642", "9.1900", SourcePin),
+				candidate("45.4640", "9.1890", SourceViewport),
+			},
+		},
+		{
+			name: "legacy parameters",
+			url:  "https://osm.org/?lat=45.4640&lon=9.1890&zoom=17",
+			want: []CoordinateCandidate{candidate("45.4640", "9.1890", SourceViewport)},
+		},
+		{
+			name:    "no position",
+			url:     "https://www.openstreetmap.org/search?query=Duomo",
+			wantErr: true,
+		},
+	})
+}
+
+func TestBingMapsExtractor(t *testing.T) {
+	runExtractorTests(t, extractorRegistry[BingMapsExtractorName](nil), []extractorTest{
+		{
+			name: "pushpin and center",
+			url:  "https://www.bing.com/maps?cp=45.46~9.18&lvl=15&sp=point.45.4642_9.19_Duomo",
+			want: []CoordinateCandidate{
+				candidate("45.4642", "9.19", SourcePin),
+				candidate("45.46", "9.18", SourceViewport),
+			},
+		},
+		{
+			name:    "no position",
+			url:     "https://www.bing.com/maps?q=Duomo",
+			wantErr: true,
+		},
+	})
+}
+
+func TestHereExtractor(t *testing.T) {
+	runExtractorTests(t, extractorRegistry[HereExtractorName](nil), []extractorTest{
+		{
+			name: "share link",
+			url:  "https://share.here.com/l/45.4642,9.19,Duomo?z=16",
+			want: []CoordinateCandidate{candidate("45.4642", "9.19", SourcePin)},
+		},
+		{
+			name: "location and map center",
+			url:  "https://wego.here.com/location/45.4642,9.19?map=45.46,9.18,15,normal",
+			want: []CoordinateCandidate{
+				candidate("45.4642", "9.19", SourcePin),
+				candidate("45.46", "9.18", SourceViewport),
+			},
+		},
+		{
+			name:    "no position",
+			url:     "https://wego.here.com/search/Duomo",
+			wantErr: true,
+		},
+	})
+}
+
+func TestPlusCodeExtractor(t *testing.T) {
+	runExtractorTests(t, plusCodeExtractor{}, []extractorTest{
+		{
+			name: "global code",
+			url:  "https://www.google.com/maps/search/8FVC9G8F+6X",
+			want: []CoordinateCandidate{candidate("47.3655625", "8.5249375", SourcePlusCode)},
+		},
+		{
+			name:    "no code",
+			url:     "https://www.google.com/maps/search/Duomo",
+			wantErr: true,
+		},
+	})
+}
+
+func TestBestCandidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []CoordinateCandidate
+		want       CoordinateSource
+		found      bool
+	}{
+		{name: "no candidates"},
+		{
+			name: "pin beats viewport",
+			candidates: []CoordinateCandidate{
+				candidate("45.46", "9.18", SourceViewport),
+				candidate("45.4642", "9.19", SourcePin),
+			},
+			want:  SourcePin,
+			found: true,
+		},
+		{
+			name: "places api beats geocoder",
+			candidates: []CoordinateCandidate{
+				candidate("45.46", "9.18", SourceGeocoder),
+				candidate("45.4642", "9.19", SourcePlacesApi),
+				candidate("45.46", "9.18", SourceViewport),
+			},
+			want:  SourcePlacesApi,
+			found: true,
+		},
+		{
+			name: "first of the same rank wins",
+			candidates: []CoordinateCandidate{
+				candidate("45.4642", "9.19", SourceQuery),
+				candidate("45.46", "9.18", SourceQuery),
+			},
+			want:  SourceQuery,
+			found: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			best, found := bestCandidate(test.candidates)
+			if found != test.found || best.Source != test.want {
+				t.Fatalf("bestCandidate() = %s %v, want %s %v", best.Source, found, test.want, test.found)
+			}
+			if found && best.Coordinates != test.candidates[indexOfSource(test.candidates, test.want)].Coordinates {
+				t.Errorf("bestCandidate() = %v, want the first %s candidate", best.Coordinates, test.want)
+			}
+		})
+	}
+}
+
+func indexOfSource(candidates []CoordinateCandidate, source CoordinateSource) int {
+	for i, candidate := range candidates {
+		if candidate.Source == source {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func TestCandidatePrecision(t *testing.T) {
+	far, near := 250.0, 10.0
+	tests := []struct {
+		candidate CoordinateCandidate
+		want      string
+	}{
+		{candidate("45.4642", "9.19", SourcePin), PrecisionExact},
+		{candidate("45.46", "9.18", SourceViewport), PrecisionApproximate},
+		{CoordinateCandidate{Source: SourceGeocoder, Approximate: true}, PrecisionApproximate},
+		{CoordinateCandidate{Source: SourcePlusCode, Uncertainty: &far}, PrecisionApproximate},
+		{CoordinateCandidate{Source: SourcePlusCode, Uncertainty: &near}, PrecisionExact},
+	}
+
+	for _, test := range tests {
+		if got := test.candidate.Precision(); got != test.want {
+			t.Errorf("Precision() of %s = %s, want %s", test.candidate.Source, got, test.want)
+		}
+	}
+}
+
+func TestRunExtractors(t *testing.T) {
+	miss := stubExtractor{err: fmt.Errorf("%w: no pin", errNoMatch)}
+	quota := stubExtractor{err: errors.New("exceeded the number of requests today")}
+	viewport := stubExtractor{candidates: []CoordinateCandidate{candidate("45.46", "9.18", SourceViewport)}}
+
+	s := &Service{Extractors: []Extractor{miss, viewport}}
+	candidates, err := s.runExtractors(context.Background(), "https://www.google.com/maps")
+	if err != nil || len(candidates) != 1 {
+		t.Errorf("runExtractors() = %v, %v, want the viewport and no error", candidates, err)
+	}
+
+	s = &Service{Extractors: []Extractor{quota, viewport}}
+	candidates, err = s.runExtractors(context.Background(), "https://www.google.com/maps")
+	if err == nil || len(candidates) != 1 {
+		t.Errorf("runExtractors() = %v, %v, want the viewport and the quota error", candidates, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s = &Service{Extractors: []Extractor{miss}}
+	if _, err := s.runExtractors(ctx, "https://www.google.com/maps"); !errors.Is(err, context.Canceled) {
+		t.Errorf("runExtractors() error = %v, want %v", err, context.Canceled)
+	}
+}
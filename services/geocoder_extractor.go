@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps-to-waze-api/models"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	geocoderResultsLimit = 5
+	// Below this Geoapify confidence the match is likely a nearby street or
+	// just the city
+	geocoderExactConfidence = 0.8
+	// Past this distance from the map the user was looking at, in meters,
+	// the match is more likely a namesake than the place
+	geocoderMaxViewportDistance = 25000
+)
+
+// geocoderExtractor forward geocodes the text searched in links that carry
+// neither coordinates nor a place id, like maps.google.com/?q=Via+Roma+1
+type geocoderExtractor struct {
+	service *Service
+}
+
+func (geocoderExtractor) Name() string {
+	return GeocoderExtractorName
+}
+
+func (e geocoderExtractor) Extract(ctx context.Context, Url string) ([]CoordinateCandidate, error) {
+	text, found := getSearchTextFromUrl(Url)
+	if !found {
+		return nil, fmt.Errorf("%w: no search text in the URL", errNoMatch)
+	}
+
+	// Searches are run around the @ viewport, like Google Maps does
+	var viewport *models.Coordinates
+	if match := urlAtPattern.FindStringSubmatch(Url); match != nil && isValidCoordinates(match[1], match[2]) {
+		viewport = &models.Coordinates{Latitude: match[1], Longitude: match[2]}
+	}
+
+	results, err := e.service.geocodeText(ctx, text, geocoderResultsLimit, viewport)
+	if err != nil {
+		return nil, err
+	}
+
+	if viewport != nil {
+		if distance, ok := distanceMeters(*viewport, results[0].Coordinates); ok && distance > geocoderMaxViewportDistance {
+			slog.InfoContext(ctx, fmt.Sprintf("the geocoded result is %.0f m from the viewport, keeping the viewport", distance))
+			return []CoordinateCandidate{{Coordinates: *viewport, Source: SourceViewport}}, nil
+		}
+	}
+
+	best := CoordinateCandidate{
+		Coordinates: results[0].Coordinates,
+		Source:      SourceGeocoder,
+		Approximate: results[0].Confidence < geocoderExactConfidence,
+	}
+
+	for _, result := range results[1:] {
+		best.Alternates = append(best.Alternates, models.GeocodeAlternate{
+			Formatted:   result.Formatted,
+			Coordinates: result.Coordinates,
+			Confidence:  result.Confidence,
+			URL:         getWazeLinkFromCoordinates(result.Coordinates),
+		})
+	}
+
+	return []CoordinateCandidate{best}, nil
+}
+
+// getSearchTextFromUrl returns what the user searched for, either in the
+// query parameters or in the /search/ and /place/ path segments
+func getSearchTextFromUrl(Url string) (string, bool) {
+	parsedUrl, err := url.Parse(Url)
+	if err != nil {
+		return "", false
+	}
+
+	var texts []string
+	for _, param := range []string{"q", "query", "address", "where1"} {
+		texts = append(texts, parsedUrl.Query().Get(param))
+	}
+
+	segments := strings.Split(parsedUrl.Path, "/")
+	for i, segment := range segments[:len(segments)-1] {
+		if segment == "search" || segment == "place" {
+			texts = append(texts, segments[i+1])
+		}
+	}
+
+	for _, text := range texts {
+		text = strings.TrimSpace(text)
+		if text == "" || strings.HasPrefix(text, "@") {
+			continue
+		}
+		if _, err := parseCoordinatesText(text); err == nil {
+			continue
+		}
+
+		return text, true
+	}
+
+	return "", false
+}
+
+// distanceMeters returns the great circle distance between two points
+func distanceMeters(from models.Coordinates, to models.Coordinates) (float64, bool) {
+	const earthRadiusMeters = 6371000
+
+	var values [4]float64
+	for i, text := range []string{from.Latitude, from.Longitude, to.Latitude, to.Longitude} {
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return 0, false
+		}
+		values[i] = value * math.Pi / 180
+	}
+	lat1, lng1, lat2, lng2 := values[0], values[1], values[2], values[3]
+
+	a := math.Pow(math.Sin((lat2-lat1)/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin((lng2-lng1)/2), 2)
+
+	return 2 * earthRadiusMeters * math.Asin(math.Sqrt(a)), true
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"maps-to-waze-api/models"
+	"math"
+	"testing"
+)
+
+func TestDistanceMeters(t *testing.T) {
+	milan := models.Coordinates{Latitude: "45.4642", Longitude: "9.19"}
+	rome := models.Coordinates{Latitude: "41.9028", Longitude: "12.4964"}
+
+	distance, ok := distanceMeters(milan, rome)
+	if !ok || math.Abs(distance-477000) > 5000 {
+		t.Errorf("distanceMeters(Milan, Rome) = %.0f %v, want about 477 km", distance, ok)
+	}
+
+	if distance, ok := distanceMeters(milan, milan); !ok || distance != 0 {
+		t.Errorf("distanceMeters(Milan, Milan) = %.0f %v, want 0", distance, ok)
+	}
+
+	if _, ok := distanceMeters(milan, models.Coordinates{}); ok {
+		t.Errorf("distanceMeters() succeeded without coordinates")
+	}
+}
+
+func TestGetSearchTextFromUrl(t *testing.T) {
+	tests := []struct {
+		url   string
+		text  string
+		found bool
+	}{
+		{"https://www.google.com/maps?q=Via Roma 1 Milano", "Via Roma 1 Milano", true},
+		{"https://www.google.com/maps/search/pizza/@45.46,9.18,14z", "pizza", true},
+		{"https://www.google.com/maps/place/Trattoria X/@45.46,9.18,17z", "Trattoria X", true},
+		{"https://www.google.com/maps/search/45.46, 9.18", "", false},
+		{"https://www.google.com/maps/@45.46,9.18,14z", "", false},
+	}
+
+	for _, test := range tests {
+		text, found := getSearchTextFromUrl(test.url)
+		if text != test.text || found != test.found {
+			t.Errorf("getSearchTextFromUrl(%q) = %q %v, want %q %v", test.url, text, found, test.text, test.found)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps-to-waze-api/models"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+)
+
+type geocodeResult struct {
+	Coordinates models.Coordinates
+	Formatted   string
+	Confidence  float64
+}
+
+// geocodeText forward geocodes free text with the Geoapify search API,
+// the results are sorted by relevance. When near is set, results close to
+// it are preferred.
+func (s *Service) geocodeText(ctx context.Context, text string, limit int, near *models.Coordinates) ([]geocodeResult, error) {
+	slog.InfoContext(ctx, fmt.Sprintf("geocoding text: %s", text))
+
+	apiKey, isPresent := os.LookupEnv("GEOAPIFY_API_KEY")
+	if !isPresent {
+		return nil, fmt.Errorf("GEOAPIFY_API_KEY environment variable is not set")
+	}
+
+	baseUrl := "https://api.geoapify.com/v1/geocode/search"
+	params := url.Values{
+		"apiKey": {apiKey},
+		"text":   {text},
+		"limit":  {strconv.Itoa(limit)},
+		"lang":   {"en"},
+		"format": {"json"},
+	}
+	if near != nil {
+		params.Set("bias", fmt.Sprintf("proximity:%s,%s", near.Longitude, near.Latitude))
+	}
+	apiUrl := fmt.Sprintf("%s?%s", baseUrl, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiUrl, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if err := s.reserveGeoapifyRequest(ctx, GeoapifyGeocodingRequestTypeId, "GEOAPIFY_CREDIT_PER_REQUEST_GEOCODING"); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make the request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Check status code
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("received non-OK HTTP status: %s", resp.Status)
+	}
+
+	// Read the response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the response body: %w", err)
+	}
+
+	var geoapifyResp models.GeoapifyGeocodingResponse
+	if err := json.Unmarshal(body, &geoapifyResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the response: %w", err)
+	}
+
+	if len(geoapifyResp.Results) == 0 {
+		return nil, fmt.Errorf("%w: no results found for %q", errNoMatch, text)
+	}
+
+	results := make([]geocodeResult, 0, len(geoapifyResp.Results))
+	for _, result := range geoapifyResp.Results {
+		formatted := ""
+		if result.Formatted != nil {
+			formatted = *result.Formatted
+		}
+
+		results = append(results, geocodeResult{
+			Coordinates: models.Coordinates{
+				Latitude:  strconv.FormatFloat(result.Lat, 'f', -1, 64),
+				Longitude: strconv.FormatFloat(result.Lon, 'f', -1, 64),
+			},
+			Formatted:  formatted,
+			Confidence: result.Rank.Confidence,
+		})
+	}
+
+	return results, nil
+}
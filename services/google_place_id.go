@@ -0,0 +1,63 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"regexp"
+)
+
+// Google place IDs ("ChIJ…") are a base64 protobuf holding the two halves
+// of the ftid as fixed64 fields: 0a 12 09 <first> 11 <second>
+
+var googlePlaceIdPattern = regexp.MustCompile(`(?:query_place_id|destination_place_id|place_id)[=:](ChIJ[A-Za-z0-9_-]+)`)
+
+var placeIdHeader = []byte{0x0a, 0x12, 0x09}
+
+const placeIdSecondField = 0x11
+
+func findGooglePlaceId(Url string) string {
+	match := googlePlaceIdPattern.FindStringSubmatch(Url)
+	if match == nil {
+		return ""
+	}
+
+	return match[1]
+}
+
+func placeIdFromFtid(ftid string) (string, error) {
+	match := ftidPattern.FindStringSubmatch(ftid)
+	if match == nil {
+		return "", fmt.Errorf("invalid ftid %q", ftid)
+	}
+
+	first, firstOk := new(big.Int).SetString(match[1], 16)
+	second, secondOk := new(big.Int).SetString(match[2], 16)
+	if !firstOk || !secondOk || first.BitLen() > 64 || second.BitLen() > 64 {
+		return "", fmt.Errorf("invalid ftid %q", ftid)
+	}
+
+	raw := append([]byte{}, placeIdHeader...)
+	raw = binary.LittleEndian.AppendUint64(raw, first.Uint64())
+	raw = append(raw, placeIdSecondField)
+	raw = binary.LittleEndian.AppendUint64(raw, second.Uint64())
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func ftidFromPlaceId(placeId string) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(placeId)
+	if err != nil {
+		return "", fmt.Errorf("invalid place ID %q: %w", placeId, err)
+	}
+
+	if len(raw) != 20 || string(raw[:3]) != string(placeIdHeader) || raw[11] != placeIdSecondField {
+		return "", fmt.Errorf("place ID %q does not hold an ftid", placeId)
+	}
+
+	first := binary.LittleEndian.Uint64(raw[3:11])
+	second := binary.LittleEndian.Uint64(raw[12:20])
+
+	return fmt.Sprintf("0x%x:0x%x", first, second), nil
+}
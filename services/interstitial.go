@@ -0,0 +1,84 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// From some regions Google answers the first request with a cookie consent
+// page, and under heavy traffic with a captcha page. Both keep the page that
+// was asked for in the continue= parameter.
+
+const (
+	InterstitialConsent = "consent"
+	InterstitialCaptcha = "captcha"
+
+	maxInterstitialUnwraps = 3
+)
+
+type InterstitialError struct {
+	Kind   string
+	Url    string
+	Reason string
+}
+
+func (e *InterstitialError) Error() string {
+	return fmt.Sprintf("stuck on the google %s page %s: %s", e.Kind, e.Url, e.Reason)
+}
+
+func getInterstitialKind(pageUrl *url.URL) string {
+	host := strings.ToLower(pageUrl.Hostname())
+	if !googleHostPattern.MatchString(host) {
+		return ""
+	}
+
+	switch {
+	case strings.HasPrefix(host, "consent."):
+		return InterstitialConsent
+	case strings.HasPrefix(pageUrl.Path, "/sorry/"):
+		return InterstitialCaptcha
+	default:
+		return ""
+	}
+}
+
+// unwrapInterstitial returns the page behind consent and captcha pages,
+// other URLs are returned unchanged. The target can't be fetched again
+// without passing the interstitial, so it must already be a full map URL.
+func unwrapInterstitial(pageUrl *url.URL) (*url.URL, error) {
+	for unwraps := 0; ; unwraps++ {
+		kind := getInterstitialKind(pageUrl)
+		if kind == "" {
+			return pageUrl, nil
+		}
+		if unwraps == maxInterstitialUnwraps {
+			return nil, &InterstitialError{Kind: kind, Url: pageUrl.String(), Reason: "too many nested interstitials"}
+		}
+
+		continueUrl := pageUrl.Query().Get("continue")
+		if continueUrl == "" {
+			return nil, &InterstitialError{Kind: kind, Url: pageUrl.String(), Reason: "no continue parameter"}
+		}
+
+		target, err := url.Parse(continueUrl)
+		if err != nil {
+			return nil, &InterstitialError{Kind: kind, Url: pageUrl.String(), Reason: fmt.Sprintf("invalid continue parameter: %v", err)}
+		}
+
+		if err := validateRedirectUrl(target); err != nil {
+			return nil, &InterstitialError{Kind: kind, Url: pageUrl.String(), Reason: fmt.Sprintf("continue parameter refused: %v", err)}
+		}
+
+		if isShortLinkHost(target.Hostname()) {
+			return nil, &InterstitialError{Kind: kind, Url: pageUrl.String(), Reason: "the continue parameter is a short link"}
+		}
+
+		pageUrl = target
+	}
+}
+
+func isShortLinkHost(host string) bool {
+	host = strings.ToLower(host)
+	return host == "goo.gl" || strings.HasSuffix(host, ".goo.gl") || host == "g.co"
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+)
+
+// A redirect chain recorded from the EU, every hop is served by the fixture
+// server whatever its host
+type recordedChain struct {
+	Name       string        `json:"name"`
+	Url        string        `json:"url"`
+	Hops       []recordedHop `json:"hops"`
+	WantUrl    string        `json:"want_url"`
+	WantError  string        `json:"want_error"`
+	WantReason string        `json:"want_reason"`
+}
+
+type recordedHop struct {
+	Url      string `json:"url"`
+	Status   int    `json:"status"`
+	Location string `json:"location"`
+}
+
+func loadRecordedChains(t *testing.T) []recordedChain {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/interstitial_chains.json")
+	if err != nil {
+		t.Fatalf("failed to read the fixture chains: %v", err)
+	}
+
+	var chains []recordedChain
+	if err := json.Unmarshal(data, &chains); err != nil {
+		t.Fatalf("failed to parse the fixture chains: %v", err)
+	}
+
+	return chains
+}
+
+// replayChain serves the hops of chain and returns a redirect client that
+// sends every request to that server, keeping the hop checks of the real one
+func replayChain(t *testing.T, chain recordedChain) *http.Client {
+	t.Helper()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested := "https://" + r.Host + r.RequestURI
+		for _, hop := range chain.Hops {
+			if hop.Url == requested {
+				if hop.Location != "" {
+					w.Header().Set("Location", hop.Location)
+				}
+				w.WriteHeader(hop.Status)
+				return
+			}
+		}
+
+		t.Errorf("unexpected request to %s", requested)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+
+	client := newRedirectClient(5 * time.Second)
+	transport := client.Transport.(*http.Transport)
+	transport.DialContext = func(ctx context.Context, network string, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	}
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+
+	return client
+}
+
+func TestResolveUrlInterstitials(t *testing.T) {
+	for _, chain := range loadRecordedChains(t) {
+		t.Run(chain.Name, func(t *testing.T) {
+			s := newTestService(t)
+			s.RedirectClient = replayChain(t, chain)
+
+			resolution, err := s.resolveUrl(context.Background(), chain.Url)
+
+			if chain.WantError != "" {
+				var interstitialErr *InterstitialError
+				if !errors.As(err, &interstitialErr) {
+					t.Fatalf("resolveUrl() = %v, %v, want an InterstitialError", resolution.Url, err)
+				}
+				if interstitialErr.Kind != chain.WantError {
+					t.Errorf("resolveUrl() stopped on a %s page, want %s", interstitialErr.Kind, chain.WantError)
+				}
+				if interstitialErr.Reason != chain.WantReason {
+					t.Errorf("resolveUrl() failed with %q, want %q", interstitialErr.Reason, chain.WantReason)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("resolveUrl() failed: %v", err)
+			}
+			if resolution.CanonicalUrl != chain.WantUrl {
+				t.Errorf("resolveUrl() = %s, want %s", resolution.CanonicalUrl, chain.WantUrl)
+			}
+		})
+	}
+}
+
+func TestUnwrapInterstitial(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		want       string
+		wantReason string
+	}{
+		{
+			name: "map page",
+			url:  "https://www.google.com/maps/place/Duomo",
+			want: "https://www.google.com/maps/place/Duomo",
+		},
+		{
+			name: "consent",
+			url:  "https://consent.google.de/m?continue=https%3A%2F%2Fwww.google.de%2Fmaps%2Fplace%2FDuomo&gl=DE",
+			want: "https://www.google.de/maps/place/Duomo",
+		},
+		{
+			name:       "continue to another domain",
+			url:        "https://consent.google.com/m?continue=https%3A%2F%2Fevil.example%2F",
+			wantReason: "continue parameter refused: host \"evil.example\" is not an allowed map domain",
+		},
+		{
+			name:       "captcha on a short link",
+			url:        "https://www.google.com/sorry/index?continue=https%3A%2F%2Fmaps.app.goo.gl%2Fabc",
+			wantReason: "the continue parameter is a short link",
+		},
+		{
+			name:       "consent without continue",
+			url:        "https://consent.google.com/m?gl=IT",
+			wantReason: "no continue parameter",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pageUrl, _ := url.Parse(test.url)
+			got, err := unwrapInterstitial(pageUrl)
+
+			if test.wantReason != "" {
+				var interstitialErr *InterstitialError
+				if !errors.As(err, &interstitialErr) || interstitialErr.Reason != test.wantReason {
+					t.Fatalf("unwrapInterstitial() = %v, %v, want %q", got, err, test.wantReason)
+				}
+				return
+			}
+
+			if err != nil || got.String() != test.want {
+				t.Errorf("unwrapInterstitial() = %v, %v, want %s", got, err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"fmt"
+	"maps-to-waze-api/internal/coordformat"
+	"maps-to-waze-api/models"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	ProviderGeoUri      = "geo_uri"
+	ProviderCoordinates = "coordinates"
+)
+
+func isHttpUrl(Url string) bool {
+	parsedUrl, err := url.Parse(strings.TrimSpace(Url))
+	if err != nil {
+		return false
+	}
+
+	scheme := strings.ToLower(parsedUrl.Scheme)
+	return (scheme == "http" || scheme == "https") && parsedUrl.Host != ""
+}
+
+// parseLocalInput handles the inputs that are not web links, like geo: URIs,
+// global plus codes and coordinates typed by hand in any format, without
+// any network call
+func parseLocalInput(input string) (CoordinateCandidate, string, error) {
+	input = strings.TrimSpace(input)
+
+	if strings.HasPrefix(strings.ToLower(input), "geo:") {
+		candidate, err := parseGeoUri(input)
+		return candidate, ProviderGeoUri, err
+	}
+
+	if code, found := findGlobalPlusCode(input); found && strings.EqualFold(code, input) {
+		candidate, err := plusCodeCandidate(code)
+		return candidate, ProviderPlusCode, err
+	}
+
+	coordinates, err := parseCoordinatesText(input)
+	if err != nil {
+		return CoordinateCandidate{}, "", err
+	}
+
+	return CoordinateCandidate{Coordinates: coordinates, Source: SourceQuery}, ProviderCoordinates, nil
+}
+
+// parseGeoUri parses RFC 5870 URIs like geo:45.46,9.19;u=30 and the Android
+// flavour geo:0,0?q=45.46,9.19(Label)
+func parseGeoUri(uri string) (CoordinateCandidate, error) {
+	uri = uri[len("geo:"):]
+
+	path, rawQuery, _ := strings.Cut(uri, "?")
+	params := strings.Split(path, ";")
+
+	coordinates := strings.Split(params[0], ",")
+	if len(coordinates) < 2 || len(coordinates) > 3 {
+		return CoordinateCandidate{}, fmt.Errorf("invalid geo URI coordinates %q", params[0])
+	}
+
+	candidate := CoordinateCandidate{Source: SourcePin}
+	candidate.Coordinates, _ = normalizeCoordinates(coordinates[0], coordinates[1])
+
+	for _, param := range params[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		switch strings.ToLower(key) {
+		case "crs":
+			if !strings.EqualFold(value, "wgs84") {
+				return CoordinateCandidate{}, fmt.Errorf("unsupported geo URI coordinate reference system %q", value)
+			}
+		case "u":
+			uncertainty, err := strconv.ParseFloat(value, 64)
+			if err != nil || uncertainty < 0 {
+				return CoordinateCandidate{}, fmt.Errorf("invalid geo URI uncertainty %q", value)
+			}
+			candidate.Uncertainty = &uncertainty
+		}
+	}
+
+	// Android uses geo:0,0?q=lat,lon(label) to drop a pin
+	query, err := url.ParseQuery(rawQuery)
+	if err == nil && query.Get("q") != "" {
+		q, _, _ := strings.Cut(query.Get("q"), "(")
+		if parsed, err := parseCoordinatesText(q); err == nil {
+			candidate.Coordinates = parsed
+		}
+	}
+
+	if !isValidCoordinates(candidate.Coordinates.Latitude, candidate.Coordinates.Longitude) {
+		return CoordinateCandidate{}, fmt.Errorf("invalid geo URI coordinates %q", params[0])
+	}
+
+	return candidate, nil
+}
+
+// parseCoordinatesText parses any format known to coordformat: decimal
+// degrees with signs or hemisphere letters, DMS, DDM, UTM and MGRS
+func parseCoordinatesText(text string) (models.Coordinates, error) {
+	point, _, err := coordformat.Parse(text)
+	if err != nil {
+		return models.Coordinates{}, err
+	}
+
+	return coordinatesFromPoint(point), nil
+}
+
+// normalizeCoordinates parses the two numbers and formats them again, so a
+// "+45.46" or "45.4600" typed by the user ends up as 45.46 in the links
+func normalizeCoordinates(latitude string, longitude string) (models.Coordinates, bool) {
+	if !isValidCoordinates(latitude, longitude) {
+		return models.Coordinates{}, false
+	}
+
+	lat, _ := strconv.ParseFloat(latitude, 64)
+	lng, _ := strconv.ParseFloat(longitude, 64)
+
+	return coordinatesFromPoint(coordformat.Point{Latitude: lat, Longitude: lng}), true
+}
+
+func coordinatesFromPoint(point coordformat.Point) models.Coordinates {
+	return models.Coordinates{
+		Latitude:  strconv.FormatFloat(math.Round(point.Latitude*1e7)/1e7, 'f', -1, 64),
+		Longitude: strconv.FormatFloat(math.Round(point.Longitude*1e7)/1e7, 'f', -1, 64),
+	}
+}
@@ -0,0 +1,47 @@
+package services
+
+import "testing"
+
+func TestParseLocalInput(t *testing.T) {
+	tests := []struct {
+		input    string
+		lat, lng string
+		source   CoordinateSource
+	}{
+		{"geo:45.46,9.19", "45.46", "9.19", SourcePin},
+		{"geo:+45.46,+9.19", "45.46", "9.19", SourcePin},
+		{"geo:45.4600,9.1900;u=30", "45.46", "9.19", SourcePin},
+		{"geo:0,0?q=45.46,9.19(Duomo)", "45.46", "9.19", SourcePin},
+		{"45.4642, 9.1900", "45.4642", "9.19", SourceQuery},
+		{"45.4642N 9.19E", "45.4642", "9.19", SourceQuery},
+	}
+
+	for _, test := range tests {
+		candidate, _, err := parseLocalInput(test.input)
+		if err != nil {
+			t.Errorf("parseLocalInput(%q) failed: %v", test.input, err)
+			continue
+		}
+		if candidate.Coordinates.Latitude != test.lat || candidate.Coordinates.Longitude != test.lng || candidate.Source != test.source {
+			t.Errorf("parseLocalInput(%q) = %v %s, want %s,%s %s", test.input,
+				candidate.Coordinates, candidate.Source, test.lat, test.lng, test.source)
+		}
+	}
+
+	for _, input := range []string{"geo:95,9.19", "geo:45.46", "geo:45.46,9.19;crs=utm"} {
+		if _, _, err := parseLocalInput(input); err == nil {
+			t.Errorf("parseLocalInput(%q) succeeded, want an error", input)
+		}
+	}
+}
+
+func TestParseCoordinatePair(t *testing.T) {
+	coordinates, found := parseCoordinatePair("+45.4600,+9.19")
+	if !found || coordinates.Latitude != "45.46" || coordinates.Longitude != "9.19" {
+		t.Fatalf("parseCoordinatePair() = %v %v, want 45.46,9.19", coordinates, found)
+	}
+
+	if link := getWazeLinkFromCoordinates(coordinates); link != "https://www.waze.com/ul?ll=45.46,9.19&navigate=yes" {
+		t.Errorf("getWazeLinkFromCoordinates() = %s", link)
+	}
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"fmt"
+	"maps-to-waze-api/models"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Google Maps stores the page state in a flattened protobuf passed as the
+// data= parameter, and embedded maps as the pb= parameter. Every token has
+// the form !<field><type><value> and a token of type 'm' opens a message
+// that spans the next <value> tokens.
+
+var (
+	dataParamPattern  = regexp.MustCompile(`data=(![^?&#]+)`)
+	embedParamPattern = regexp.MustCompile(`[?&]pb=(![^&#]+)`)
+	dataTokenPattern  = regexp.MustCompile(`^(\d+)([a-z])(.*)$`)
+	ftidPattern       = regexp.MustCompile(`^0x([0-9a-fA-F]+):0x([0-9a-fA-F]+)$`)
+)
+
+type dataParamNode struct {
+	Field    int
+	Type     byte
+	Value    string
+	Children []*dataParamNode
+}
+
+type mapsDataParam struct {
+	Root []*dataParamNode
+}
+
+type dataParamPlace struct {
+	Latitude  string
+	Longitude string
+	Ftid      string
+	Cid       string
+	Name      string
+}
+
+// parseDataParamFromUrl decodes the data= parameter, or the pb= parameter
+// of embed URLs
+func parseDataParamFromUrl(Url string) (mapsDataParam, error) {
+	match := dataParamPattern.FindStringSubmatch(Url)
+	if match == nil {
+		match = embedParamPattern.FindStringSubmatch(Url)
+	}
+	if match == nil {
+		return mapsDataParam{}, fmt.Errorf("no data parameter in the URL")
+	}
+
+	return parseDataParam(match[1])
+}
+
+func parseDataParam(raw string) (mapsDataParam, error) {
+	var tokens []*dataParamNode
+	for _, part := range strings.Split(strings.TrimPrefix(raw, "!"), "!") {
+		if part == "" {
+			continue
+		}
+
+		match := dataTokenPattern.FindStringSubmatch(part)
+		if match == nil {
+			return mapsDataParam{}, fmt.Errorf("malformed data parameter token %q", part)
+		}
+
+		field, err := strconv.Atoi(match[1])
+		if err != nil {
+			return mapsDataParam{}, fmt.Errorf("malformed field number in token %q: %w", part, err)
+		}
+
+		tokens = append(tokens, &dataParamNode{Field: field, Type: match[2][0], Value: match[3]})
+	}
+
+	root, _, err := buildDataParamTree(tokens, len(tokens))
+	if err != nil {
+		return mapsDataParam{}, err
+	}
+
+	return mapsDataParam{Root: root}, nil
+}
+
+// buildDataParamTree consumes count tokens and returns the nodes built from
+// them together with the tokens that were not consumed.
+func buildDataParamTree(tokens []*dataParamNode, count int) ([]*dataParamNode, []*dataParamNode, error) {
+	var nodes []*dataParamNode
+
+	for count > 0 {
+		if len(tokens) == 0 {
+			return nil, nil, fmt.Errorf("data parameter message is truncated")
+		}
+
+		node := tokens[0]
+		tokens = tokens[1:]
+		count--
+
+		if node.Type == 'm' {
+			size, err := strconv.Atoi(node.Value)
+			if err != nil || size < 0 || size > count {
+				return nil, nil, fmt.Errorf("invalid message size %q", node.Value)
+			}
+
+			node.Children, tokens, err = buildDataParamTree(tokens, size)
+			if err != nil {
+				return nil, nil, err
+			}
+			count -= size
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	return nodes, tokens, nil
+}
+
+func (n *dataParamNode) child(field int, typ byte) *dataParamNode {
+	for _, c := range n.Children {
+		if c.Field == field && c.Type == typ {
+			return c
+		}
+	}
+
+	return nil
+}
+
+func walkDataParam(nodes []*dataParamNode, visit func(*dataParamNode) bool) bool {
+	for _, node := range nodes {
+		if !visit(node) {
+			return false
+		}
+		if !walkDataParam(node.Children, visit) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Place extracts the pin position, the feature id and the place name. The
+// pin lives in a message holding a !3d latitude and a !4d longitude, the
+// feature id is the first "0x…:0x…" string and the name is the !2s string
+// stored next to it.
+func (p mapsDataParam) Place() dataParamPlace {
+	var place dataParamPlace
+
+	root := &dataParamNode{Type: 'm', Children: p.Root}
+	walkDataParam([]*dataParamNode{root}, func(node *dataParamNode) bool {
+		if place.Latitude == "" && node.Type == 'm' {
+			lat, lng := node.child(3, 'd'), node.child(4, 'd')
+			if lat != nil && lng != nil && isValidCoordinates(lat.Value, lng.Value) {
+				place.Latitude, place.Longitude = lat.Value, lng.Value
+			}
+		}
+
+		if place.Ftid == "" && node.Type == 'm' {
+			for _, c := range node.Children {
+				if c.Type == 's' && ftidPattern.MatchString(c.Value) {
+					place.Ftid = c.Value
+					place.Cid, _ = cidFromFtid(c.Value)
+					if name := node.child(2, 's'); name != nil {
+						place.Name = name.Value
+					}
+					break
+				}
+			}
+		}
+
+		return place.Latitude == "" || place.Ftid == ""
+	})
+
+	return place
+}
+
+// EmbedCamera returns the center of an embedded map, stored in the pb=
+// parameter as !1m3!1d<altitude>!2d<lng>!3d<lat>
+func (p mapsDataParam) EmbedCamera() (models.Coordinates, bool) {
+	var camera models.Coordinates
+
+	root := &dataParamNode{Type: 'm', Children: p.Root}
+	walkDataParam([]*dataParamNode{root}, func(node *dataParamNode) bool {
+		if node.Type != 'm' || node.child(1, 'd') == nil {
+			return true
+		}
+
+		lng, lat := node.child(2, 'd'), node.child(3, 'd')
+		if lat != nil && lng != nil && isValidCoordinates(lat.Value, lng.Value) {
+			camera = models.Coordinates{Latitude: lat.Value, Longitude: lng.Value}
+			return false
+		}
+
+		return true
+	})
+
+	return camera, camera.Latitude != ""
+}
+
+// cidFromFtid converts the second half of a "0x…:0x…" feature id into the
+// decimal CID understood by the Places API.
+func cidFromFtid(ftid string) (string, error) {
+	match := ftidPattern.FindStringSubmatch(ftid)
+	if match == nil {
+		return "", fmt.Errorf("invalid ftid %q", ftid)
+	}
+
+	cid, success := new(big.Int).SetString(match[2], 16)
+	if !success {
+		return "", fmt.Errorf("invalid ftid %q", ftid)
+	}
+
+	return cid.String(), nil
+}
+
+func isValidCoordinates(latitude string, longitude string) bool {
+	lat, err := strconv.ParseFloat(latitude, 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return false
+	}
+
+	lng, err := strconv.ParseFloat(longitude, 64)
+	if err != nil || lng < -180 || lng > 180 {
+		return false
+	}
+
+	return true
+}
+
+// DirectionsWaypoints returns the stops of a directions data parameter.
+// Every stop is a !1m message holding the feature id in !1m1!1s and the
+// position in !2m2!1d<lng>!2d<lat>, an empty !1m0 is the current location.
+func (p mapsDataParam) DirectionsWaypoints() []directionsWaypoint {
+	var waypoints []directionsWaypoint
+
+	root := &dataParamNode{Type: 'm', Children: p.Root}
+	walkDataParam([]*dataParamNode{root}, func(node *dataParamNode) bool {
+		var stops []*dataParamNode
+		for _, c := range node.Children {
+			if c.Field == 1 && c.Type == 'm' {
+				stops = append(stops, c)
+			}
+		}
+		if len(stops) < 2 || node.child(3, 'e') == nil && !hasWaypointData(stops) {
+			return true
+		}
+
+		for _, stop := range stops {
+			var waypoint directionsWaypoint
+			if feature := stop.child(1, 'm'); feature != nil {
+				if ftid := feature.child(1, 's'); ftid != nil && ftidPattern.MatchString(ftid.Value) {
+					waypoint.Ftid = ftid.Value
+				}
+			}
+			if position := stop.child(2, 'm'); position != nil {
+				lng, lat := position.child(1, 'd'), position.child(2, 'd')
+				if lat != nil && lng != nil && isValidCoordinates(lat.Value, lng.Value) {
+					waypoint.Coordinates = models.Coordinates{Latitude: lat.Value, Longitude: lng.Value}
+				}
+			}
+			waypoints = append(waypoints, waypoint)
+		}
+
+		return false
+	})
+
+	return waypoints
+}
+
+func hasWaypointData(stops []*dataParamNode) bool {
+	for _, stop := range stops {
+		if stop.child(1, 'm') != nil || stop.child(2, 'm') != nil {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+type recordedDataParam struct {
+	Name      string `json:"name"`
+	Url       string `json:"url"`
+	Waypoints []struct {
+		Ftid      string `json:"ftid"`
+		Latitude  string `json:"latitude"`
+		Longitude string `json:"longitude"`
+	} `json:"waypoints"`
+}
+
+func TestDirectionsWaypoints(t *testing.T) {
+	data, err := os.ReadFile("testdata/directions_data_params.json")
+	if err != nil {
+		t.Fatalf("failed to read the fixture: %v", err)
+	}
+
+	var fixtures []recordedDataParam
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		t.Fatalf("failed to parse the fixture: %v", err)
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			dataParam, err := parseDataParamFromUrl(fixture.Url)
+			if err != nil {
+				t.Fatalf("parseDataParamFromUrl() failed: %v", err)
+			}
+
+			waypoints := dataParam.DirectionsWaypoints()
+			if len(waypoints) != len(fixture.Waypoints) {
+				t.Fatalf("DirectionsWaypoints() = %+v, want %d waypoints", waypoints, len(fixture.Waypoints))
+			}
+			for i, want := range fixture.Waypoints {
+				got := waypoints[i]
+				if got.Ftid != want.Ftid || got.Coordinates.Latitude != want.Latitude || got.Coordinates.Longitude != want.Longitude {
+					t.Errorf("waypoint %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
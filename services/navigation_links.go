@@ -0,0 +1,104 @@
+package services
+
+import (
+	"fmt"
+	"maps-to-waze-api/models"
+	"sort"
+	"strings"
+)
+
+type linkFormatter func(coordinates models.Coordinates) string
+
+const (
+	LinkTargetWaze          = "waze"
+	LinkTargetGeo           = "geo"
+	LinkTargetAppleMaps     = "apple_maps"
+	LinkTargetGoogleMaps    = "google_maps"
+	LinkTargetOsmAnd        = "osmand"
+	LinkTargetSygic         = "sygic"
+	LinkTargetHere          = "here"
+	LinkTargetAndroidIntent = "android_intent"
+)
+
+var linkFormatters = map[string]linkFormatter{
+	LinkTargetWaze:          getWazeLinkFromCoordinates,
+	LinkTargetGeo:           getGeoUriFromCoordinates,
+	LinkTargetAppleMaps:     getAppleMapsLinkFromCoordinates,
+	LinkTargetGoogleMaps:    getGoogleMapsLinkFromCoordinates,
+	LinkTargetOsmAnd:        getOsmAndLinkFromCoordinates,
+	LinkTargetSygic:         getSygicLinkFromCoordinates,
+	LinkTargetHere:          getHereLinkFromCoordinates,
+	LinkTargetAndroidIntent: getAndroidIntentFromCoordinates,
+}
+
+func validateLinkTargets(targets []string) error {
+	for _, target := range targets {
+		if _, found := linkFormatters[target]; !found {
+			return fmt.Errorf("unknown link target %q, valid targets are: %s", target, strings.Join(linkTargetNames(), ", "))
+		}
+	}
+
+	return nil
+}
+
+func linkTargetNames() []string {
+	names := make([]string, 0, len(linkFormatters))
+	for name := range linkFormatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// getNavigationLinks builds a link for every target, or for all of them
+// when no target is given
+func getNavigationLinks(coordinates models.Coordinates, targets []string) map[string]string {
+	if len(targets) == 0 {
+		targets = linkTargetNames()
+	}
+
+	links := make(map[string]string, len(targets))
+	for _, target := range targets {
+		if formatter, found := linkFormatters[target]; found {
+			links[target] = formatter(coordinates)
+		}
+	}
+
+	return links
+}
+
+func getWazeLinkFromCoordinates(coordinates models.Coordinates) string {
+	return fmt.Sprintf("https://www.waze.com/ul?ll=%s,%s&navigate=yes", coordinates.Latitude, coordinates.Longitude)
+}
+
+// RFC 5870
+func getGeoUriFromCoordinates(coordinates models.Coordinates) string {
+	return fmt.Sprintf("geo:%s,%s", coordinates.Latitude, coordinates.Longitude)
+}
+
+func getAppleMapsLinkFromCoordinates(coordinates models.Coordinates) string {
+	return fmt.Sprintf("https://maps.apple.com/?ll=%s,%s&q=%s,%s", coordinates.Latitude, coordinates.Longitude, coordinates.Latitude, coordinates.Longitude)
+}
+
+func getGoogleMapsLinkFromCoordinates(coordinates models.Coordinates) string {
+	return fmt.Sprintf("https://www.google.com/maps/search/?api=1&query=%s,%s", coordinates.Latitude, coordinates.Longitude)
+}
+
+func getOsmAndLinkFromCoordinates(coordinates models.Coordinates) string {
+	return fmt.Sprintf("https://osmand.net/map?pin=%s,%s#15/%s/%s", coordinates.Latitude, coordinates.Longitude, coordinates.Latitude, coordinates.Longitude)
+}
+
+// Sygic wants the longitude first
+func getSygicLinkFromCoordinates(coordinates models.Coordinates) string {
+	return fmt.Sprintf("com.sygic.aura://coordinate|%s|%s|drive", coordinates.Longitude, coordinates.Latitude)
+}
+
+func getHereLinkFromCoordinates(coordinates models.Coordinates) string {
+	return fmt.Sprintf("https://share.here.com/l/%s,%s", coordinates.Latitude, coordinates.Longitude)
+}
+
+// The intent lets Android pick any installed app that handles map links
+func getAndroidIntentFromCoordinates(coordinates models.Coordinates) string {
+	return fmt.Sprintf("intent://maps.google.com/maps?daddr=%s,%s#Intent;scheme=https;action=android.intent.action.VIEW;end", coordinates.Latitude, coordinates.Longitude)
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"maps-to-waze-api/models"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// The Google Maps page describes the place in a few spots, the static map
+// used as preview image is centered on it, some pages carry schema.org
+// coordinates and the app state starts with the camera position.
+
+var (
+	metaTagPattern        = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	metaAttrPattern       = regexp.MustCompile(`(?s)([\w:-]+)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	appStateCameraPattern = regexp.MustCompile(`APP_INITIALIZATION_STATE\s*=\s*\[\[\[\s*-?[\d.]+\s*,\s*(-?[\d.]+)\s*,\s*(-?[\d.]+)\s*\]`)
+)
+
+// pageScrapeExtractor fetches the resolved Google Maps page and reads the
+// coordinates from its HTML, it's the last resort before geocoding
+type pageScrapeExtractor struct {
+	service *Service
+}
+
+func (pageScrapeExtractor) Name() string {
+	return PageScrapeExtractorName
+}
+
+func (e pageScrapeExtractor) Extract(ctx context.Context, Url string) ([]CoordinateCandidate, error) {
+	if provider := detectProvider(Url); provider != ProviderGoogle {
+		return nil, fmt.Errorf("%w: cannot scrape a %s page", errNoMatch, provider)
+	}
+
+	page, err := e.service.fetchPage(ctx, Url)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseMapsPage(page)
+}
+
+// fetchPage downloads a page with the same restrictions as the redirects,
+// the body is capped at maxRedirectBodySize
+func (s *Service) fetchPage(ctx context.Context, Url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, Url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request to %s: %w", Url, err)
+	}
+
+	if err := validateRedirectUrl(req.URL); err != nil {
+		return "", fmt.Errorf("refused to fetch %s: %w", Url, err)
+	}
+
+	resp, err := s.RedirectClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to make the request to %s: %w", Url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("received non-OK HTTP status: %s", resp.Status)
+	}
+
+	if getInterstitialKind(resp.Request.URL) != "" {
+		return "", &InterstitialError{Kind: getInterstitialKind(resp.Request.URL), Url: resp.Request.URL.String(), Reason: "the page can't be scraped"}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRedirectBodySize+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read the response body: %w", err)
+	}
+	if len(body) > maxRedirectBodySize {
+		return "", &ResponseTooLargeError{Size: int64(len(body)), Limit: maxRedirectBodySize}
+	}
+
+	return string(body), nil
+}
+
+func parseMapsPage(page string) ([]CoordinateCandidate, error) {
+	var candidates []CoordinateCandidate
+	var latitude, longitude string
+
+	for _, tag := range metaTagPattern.FindAllString(page, -1) {
+		attrs := getMetaAttributes(tag)
+
+		switch {
+		case attrs["itemprop"] == "latitude":
+			latitude = attrs["content"]
+		case attrs["itemprop"] == "longitude":
+			longitude = attrs["content"]
+		case attrs["property"] == "og:image" || attrs["itemprop"] == "image":
+			if coordinates, found := getStaticMapCenter(attrs["content"]); found {
+				candidates = append(candidates, CoordinateCandidate{Coordinates: coordinates, Source: SourcePage})
+			}
+		}
+	}
+
+	if latitude != "" && longitude != "" {
+		if coordinates, found := parseCoordinatePair(latitude + "," + longitude); found {
+			candidates = append([]CoordinateCandidate{{Coordinates: coordinates, Source: SourcePage}}, candidates...)
+		}
+	}
+
+	// The camera is only near the place
+	if match := appStateCameraPattern.FindStringSubmatch(page); match != nil && isValidCoordinates(match[2], match[1]) {
+		candidates = append(candidates, CoordinateCandidate{
+			Coordinates: models.Coordinates{Latitude: match[2], Longitude: match[1]},
+			Source:      SourcePage,
+			Approximate: true,
+		})
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w: no coordinates found in the page", errNoMatch)
+	}
+
+	return candidates, nil
+}
+
+func getMetaAttributes(tag string) map[string]string {
+	attrs := make(map[string]string)
+	for _, match := range metaAttrPattern.FindAllStringSubmatch(tag, -1) {
+		attrs[strings.ToLower(match[1])] = html.UnescapeString(match[2] + match[3])
+	}
+
+	return attrs
+}
+
+// getStaticMapCenter reads the center, or the last marker, of a static map
+// image URL
+func getStaticMapCenter(imageUrl string) (models.Coordinates, bool) {
+	parsedUrl, err := url.Parse(imageUrl)
+	if err != nil || !strings.Contains(parsedUrl.Path, "staticmap") {
+		return models.Coordinates{}, false
+	}
+
+	query := parsedUrl.Query()
+	for _, value := range []string{query.Get("center"), query.Get("markers")} {
+		// Markers are written as style|style|lat,lng
+		parts := strings.Split(value, "|")
+		if coordinates, found := parseCoordinatePair(parts[len(parts)-1]); found {
+			return coordinates, true
+		}
+	}
+
+	return models.Coordinates{}, false
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseMapsPage(t *testing.T) {
+	type want struct {
+		lat, lng    string
+		approximate bool
+	}
+
+	tests := []struct {
+		fixture string
+		want    []want
+	}{
+		{
+			fixture: "og_image_center.html",
+			want:    []want{{"45.4640976", "9.1919636", false}},
+		},
+		{
+			fixture: "og_image_markers.html",
+			want:    []want{{"45.4704762", "9.1793325", false}},
+		},
+		{
+			// The schema.org position comes before the preview image
+			fixture: "itemprop.html",
+			want:    []want{{"40.8517746", "14.2681244", false}, {"40.85", "14.27", false}},
+		},
+		{
+			fixture: "app_state.html",
+			want:    []want{{"45.4640976", "9.1919636", true}},
+		},
+		{fixture: "empty.html"},
+		{fixture: "malformed.html"},
+		{fixture: "consent.html"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.fixture, func(t *testing.T) {
+			page, err := os.ReadFile(filepath.Join("testdata", "maps_pages", test.fixture))
+			if err != nil {
+				t.Fatalf("failed to read the fixture: %v", err)
+			}
+
+			candidates, err := parseMapsPage(string(page))
+			if len(test.want) == 0 {
+				if !errors.Is(err, errNoMatch) {
+					t.Errorf("parseMapsPage() = %v, %v, want no match", candidates, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseMapsPage() failed: %v", err)
+			}
+
+			if len(candidates) != len(test.want) {
+				t.Fatalf("parseMapsPage() = %v, want %v", candidates, test.want)
+			}
+			for i, candidate := range candidates {
+				got := want{candidate.Coordinates.Latitude, candidate.Coordinates.Longitude, candidate.Approximate}
+				if got != test.want[i] || candidate.Source != SourcePage {
+					t.Errorf("candidate %d = %v from %s, want %v from %s", i, got, candidate.Source, test.want[i], SourcePage)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps-to-waze-api/models"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Shared lists are not in any public API, the Maps web app reads them from
+// the entitylist endpoint. The answer is a JSON array behind a )]}' guard,
+// the places are in [0][8] and each one holds its name in [2], and in [1]
+// the position as [null, null, lat, lng] and the ftid.
+
+const maxPlaceListSize = 500
+
+// Places without a position cost a Places API lookup each, the others of a
+// long list are returned unresolved
+const maxPlaceListLookups = 20
+
+// Lists are shared as /placelists/list/<id>, older links keep the id in the
+// data parameter as !11m2!2s<id>
+var placeListIdPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`/placelists/list/([A-Za-z0-9_-]+)`),
+	regexp.MustCompile(`!11m2!2s([A-Za-z0-9_-]+)!3e\d`),
+}
+
+type placeListItem struct {
+	Name        string
+	Ftid        string
+	Coordinates models.Coordinates
+}
+
+// convertPlaceList converts every place of a shared list. The response
+// points to the first place.
+func (s *Service) convertPlaceList(ctx context.Context, Url string, provider string, targets []string) (models.ConvertUrlResponse, error) {
+	listId := getPlaceListId(Url)
+	if listId == "" {
+		return models.ConvertUrlResponse{}, fmt.Errorf("ConvertUrl failed to find the list ID in the URL")
+	}
+
+	items, err := s.getPlaceListItems(ctx, listId)
+	if err != nil {
+		return models.ConvertUrlResponse{}, fmt.Errorf("ConvertUrl failed to read the list: %w", err)
+	}
+	slog.InfoContext(ctx, fmt.Sprintf("found %d places in the list", len(items)))
+
+	entries, first := s.resolvePlaceListItems(ctx, items)
+	if first == nil {
+		return models.ConvertUrlResponse{}, fmt.Errorf("ConvertUrl failed to resolve any place of the list")
+	}
+
+	response := newConvertUrlResponse(ctx, *first, provider, targets)
+	response.ListEntries = entries
+
+	return response, nil
+}
+
+// resolvePlaceListItems converts the items and returns the first one with a
+// position. Places without a position are looked up one by one, each lookup
+// counts against the quota, up to maxPlaceListLookups per request.
+func (s *Service) resolvePlaceListItems(ctx context.Context, items []placeListItem) ([]models.PlaceListEntry, *CoordinateCandidate) {
+	var entries []models.PlaceListEntry
+	var first *CoordinateCandidate
+	lookups := 0
+
+	for _, item := range items {
+		entry := models.PlaceListEntry{Name: item.Name}
+		if item.Ftid != "" {
+			entry.Cid, _ = cidFromFtid(item.Ftid)
+		}
+
+		candidate := CoordinateCandidate{Coordinates: item.Coordinates, Source: SourcePin}
+		if item.Coordinates.Latitude == "" && entry.Cid != "" {
+			if err := ctx.Err(); err != nil {
+				entry.Error = fmt.Sprintf("not looked up: %v", err)
+				entries = append(entries, entry)
+				continue
+			}
+			if lookups == maxPlaceListLookups {
+				entry.Error = fmt.Sprintf("not looked up, at most %d places of a list are looked up", maxPlaceListLookups)
+				entries = append(entries, entry)
+				continue
+			}
+			lookups++
+
+			coordinates, err := s.lookupPlace(ctx, models.Place{Cid: entry.Cid, Ftid: item.Ftid, Name: item.Name})
+			if err != nil {
+				slog.WarnContext(ctx, fmt.Sprintf("failed to look up %s: %v", item.Name, err))
+				entry.Error = err.Error()
+				entries = append(entries, entry)
+				continue
+			}
+			candidate = CoordinateCandidate{Coordinates: coordinates, Source: SourcePlacesApi}
+		}
+
+		if candidate.Coordinates.Latitude == "" {
+			entry.Error = "the place has no position and no ftid"
+			entries = append(entries, entry)
+			continue
+		}
+
+		entry.Coordinates = candidate.Coordinates
+		entry.URL = getWazeLinkFromCoordinates(candidate.Coordinates)
+		if first == nil {
+			first = &candidate
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, first
+}
+
+func hasListEntryErrors(entries []models.PlaceListEntry) bool {
+	for _, entry := range entries {
+		if entry.Error != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func getPlaceListId(Url string) string {
+	for _, pattern := range placeListIdPatterns {
+		if match := pattern.FindStringSubmatch(Url); match != nil {
+			return match[1]
+		}
+	}
+
+	return ""
+}
+
+func (s *Service) getPlaceListItems(ctx context.Context, listId string) ([]placeListItem, error) {
+	params := url.Values{
+		"authuser": {"0"},
+		"hl":       {"en"},
+		"pb":       {fmt.Sprintf("!1m4!1s%s!2e1!3m1!1e1!2e2!3e2!4i%d!16b1", listId, maxPlaceListSize)},
+	}
+	listUrl := fmt.Sprintf("https://www.google.com/maps/preview/entitylist/getlist?%s", params.Encode())
+
+	body, err := s.fetchPage(ctx, listUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePlaceList(body)
+}
+
+func parsePlaceList(body string) ([]placeListItem, error) {
+	// Drop the )]}' line that guards against JSON hijacking
+	if strings.HasPrefix(body, ")]}'") {
+		body = body[strings.Index(body, "\n")+1:]
+	}
+
+	var data []any
+	if err := json.Unmarshal([]byte(body), &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the list: %w", err)
+	}
+
+	places, ok := jsonArrayAt(data, 0, 8)
+	if !ok {
+		return nil, fmt.Errorf("the list has no places")
+	}
+
+	var items []placeListItem
+	for _, place := range places {
+		placeArray, ok := place.([]any)
+		if !ok {
+			continue
+		}
+
+		var item placeListItem
+		if len(placeArray) > 2 {
+			item.Name, _ = placeArray[2].(string)
+		}
+		if len(placeArray) > 1 {
+			findPlaceListDetails(placeArray[1], &item)
+		}
+
+		items = append(items, item)
+	}
+
+	if len(items) == 0 {
+		return nil, fmt.Errorf("the list has no places")
+	}
+
+	return items, nil
+}
+
+// findPlaceListDetails looks for the first [null, null, lat, lng] array and
+// the first ftid string, their exact position changes between versions
+func findPlaceListDetails(node any, item *placeListItem) {
+	switch value := node.(type) {
+	case string:
+		if item.Ftid == "" && ftidPattern.MatchString(value) {
+			item.Ftid = value
+		}
+	case []any:
+		if item.Coordinates.Latitude == "" && len(value) >= 4 && value[0] == nil && value[1] == nil {
+			lat, latOk := value[2].(float64)
+			lng, lngOk := value[3].(float64)
+			latitude := strconv.FormatFloat(lat, 'f', -1, 64)
+			longitude := strconv.FormatFloat(lng, 'f', -1, 64)
+			if latOk && lngOk && isValidCoordinates(latitude, longitude) {
+				item.Coordinates = models.Coordinates{Latitude: latitude, Longitude: longitude}
+			}
+		}
+		for _, child := range value {
+			findPlaceListDetails(child, item)
+		}
+	}
+}
+
+func jsonArrayAt(data []any, path ...int) ([]any, bool) {
+	current := data
+	for _, index := range path {
+		if index >= len(current) {
+			return nil, false
+		}
+		next, ok := current[index].([]any)
+		if !ok {
+			return nil, false
+		}
+		current = next
+	}
+
+	return current, true
+}
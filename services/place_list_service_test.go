@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps-to-waze-api/models"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParsePlaceList(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    []placeListItem
+	}{
+		{
+			fixture: "milano.txt",
+			want: []placeListItem{
+				{
+					Name:        "Duomo di Milano",
+					Ftid:        "0x4786c6aec34636a1:0xab7f4e27101a2e13",
+					Coordinates: models.Coordinates{Latitude: "45.4640976", Longitude: "9.1919636"},
+				},
+				{Name: "Castello Sforzesco", Ftid: "0x4786c6ba9c2c7f2d:0x5d0c1e6a3b8f2e41"},
+				{Name: "Navigli", Coordinates: models.Coordinates{Latitude: "45.4484", Longitude: "9.1706"}},
+				{Name: "Bar senza nome"},
+				{Name: "Out of range"},
+			},
+		},
+		{fixture: "empty.txt"},
+		{fixture: "private.txt"},
+		{fixture: "sign_in.txt"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.fixture, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("testdata", "place_lists", test.fixture))
+			if err != nil {
+				t.Fatalf("failed to read the fixture: %v", err)
+			}
+
+			items, err := parsePlaceList(string(body))
+			if len(test.want) == 0 {
+				if err == nil {
+					t.Errorf("parsePlaceList() = %+v, want an error", items)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePlaceList() failed: %v", err)
+			}
+
+			if len(items) != len(test.want) {
+				t.Fatalf("parsePlaceList() = %+v, want %d places", items, len(test.want))
+			}
+			for i := range items {
+				if items[i] != test.want[i] {
+					t.Errorf("place %d = %+v, want %+v", i, items[i], test.want[i])
+				}
+			}
+		})
+	}
+}
+
+// countingPlacesProvider finds every place at the same position
+type countingPlacesProvider struct {
+	lookups int
+}
+
+func (*countingPlacesProvider) Name() string {
+	return "counting"
+}
+
+func (p *countingPlacesProvider) Lookup(ctx context.Context, place models.Place) (models.Coordinates, error) {
+	p.lookups++
+	return models.Coordinates{Latitude: "45.4642", Longitude: "9.19"}, nil
+}
+
+func placeListItemsWithoutPosition(count int) []placeListItem {
+	var items []placeListItem
+	for i := 0; i < count; i++ {
+		items = append(items, placeListItem{Name: fmt.Sprintf("place %d", i), Ftid: fmt.Sprintf("0x4786c6aec34636a1:0x%x", i+1)})
+	}
+
+	return items
+}
+
+func TestResolvePlaceListItemsCapsLookups(t *testing.T) {
+	provider := &countingPlacesProvider{}
+	s := newTestService(t)
+	s.Places = provider
+
+	items := append(placeListItemsWithoutPosition(maxPlaceListLookups+5),
+		placeListItem{Name: "Navigli", Coordinates: models.Coordinates{Latitude: "45.4484", Longitude: "9.1706"}})
+
+	entries, first := s.resolvePlaceListItems(context.Background(), items)
+	if provider.lookups != maxPlaceListLookups {
+		t.Errorf("resolvePlaceListItems() made %d lookups, want %d", provider.lookups, maxPlaceListLookups)
+	}
+	if first == nil || first.Source != SourcePlacesApi {
+		t.Errorf("resolvePlaceListItems() first = %+v, want the first looked up place", first)
+	}
+	if len(entries) != len(items) {
+		t.Fatalf("resolvePlaceListItems() returned %d entries, want %d", len(entries), len(items))
+	}
+
+	for i, entry := range entries {
+		unresolved := i >= maxPlaceListLookups && i < len(items)-1
+		if unresolved != strings.HasPrefix(entry.Error, "not looked up") {
+			t.Errorf("entry %d = %+v, unresolved %v", i, entry, unresolved)
+		}
+	}
+	if last := entries[len(entries)-1]; last.URL == "" {
+		t.Errorf("the place with a position after the cap was not converted: %+v", last)
+	}
+}
+
+func TestResolvePlaceListItemsStopsWhenCanceled(t *testing.T) {
+	provider := &countingPlacesProvider{}
+	s := newTestService(t)
+	s.Places = provider
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	entries, first := s.resolvePlaceListItems(ctx, placeListItemsWithoutPosition(3))
+	if provider.lookups != 0 || first != nil {
+		t.Errorf("resolvePlaceListItems() made %d lookups after the cancellation", provider.lookups)
+	}
+	for i, entry := range entries {
+		if !strings.Contains(entry.Error, context.Canceled.Error()) {
+			t.Errorf("entry %d = %+v, want it unresolved", i, entry)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps-to-waze-api/internal/database"
+	"maps-to-waze-api/models"
+	"strconv"
+	"time"
+)
+
+var ErrPlaceNotFound = errors.New("place not found")
+
+// lookupPlace returns the coordinates of a CID from the place table, and
+// only asks the Places API for places never seen or not verified within
+// PlaceMaxAge. The ftid and name of hint are stored with the place.
+func (s *Service) lookupPlace(ctx context.Context, hint models.Place) (models.Coordinates, error) {
+	place, found, err := database.GetPlace(ctx, s.DB, hint.Cid)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to read the place registry", "error", err)
+	}
+
+	if found && time.Since(place.LastVerified) < s.Config.PlaceMaxAge {
+		slog.InfoContext(ctx, fmt.Sprintf("place found in the registry: %s", hint.Cid))
+		return coordinatesFromPlace(place), nil
+	}
+
+	// Links like ?cid= carry no ftid, the registry may know it
+	if found && hint.Ftid == "" {
+		hint.Ftid = place.Ftid
+	}
+
+	coordinates, err := s.Places.Lookup(ctx, hint)
+	if err != nil {
+		// A stale position is better than none
+		if found {
+			slog.WarnContext(ctx, "failed to verify the place, using the stored position", "error", err)
+			return coordinatesFromPlace(place), nil
+		}
+		return models.Coordinates{}, err
+	}
+
+	hint.Latitude, _ = strconv.ParseFloat(coordinates.Latitude, 64)
+	hint.Longitude, _ = strconv.ParseFloat(coordinates.Longitude, 64)
+	if err := database.UpsertPlace(ctx, s.DB, hint); err != nil {
+		slog.WarnContext(ctx, "failed to write the place registry", "error", err)
+	}
+
+	return coordinates, nil
+}
+
+func coordinatesFromPlace(place models.Place) models.Coordinates {
+	return models.Coordinates{
+		Latitude:  strconv.FormatFloat(place.Latitude, 'f', -1, 64),
+		Longitude: strconv.FormatFloat(place.Longitude, 'f', -1, 64),
+	}
+}
+
+func (s *Service) ListPlaces(ctx context.Context, limit int, offset int) ([]models.Place, error) {
+	places, err := database.ListPlaces(ctx, s.DB, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the places: %w", err)
+	}
+
+	return places, nil
+}
+
+func (s *Service) UpdatePlace(ctx context.Context, cid string, update models.PlaceUpdateRequest) (models.Place, error) {
+	if update.Latitude != nil && (*update.Latitude < -90 || *update.Latitude > 90) {
+		return models.Place{}, fmt.Errorf("latitude must be between -90 and 90")
+	}
+	if update.Longitude != nil && (*update.Longitude < -180 || *update.Longitude > 180) {
+		return models.Place{}, fmt.Errorf("longitude must be between -180 and 180")
+	}
+	if update.Ftid != nil && *update.Ftid != "" && !ftidPattern.MatchString(*update.Ftid) {
+		return models.Place{}, fmt.Errorf("invalid ftid %q", *update.Ftid)
+	}
+
+	place, found, err := database.UpdatePlace(ctx, s.DB, cid, update)
+	if err != nil {
+		return models.Place{}, fmt.Errorf("failed to update the place: %w", err)
+	}
+	if !found {
+		return models.Place{}, ErrPlaceNotFound
+	}
+
+	slog.InfoContext(ctx, fmt.Sprintf("place corrected: %s", cid))
+
+	// Conversions cached with the old position would outlive the correction
+	if err := s.evictPlaceConversions(ctx, cid); err != nil {
+		slog.ErrorContext(ctx, "the old position stays cached", "error", err)
+	}
+
+	return place, nil
+}
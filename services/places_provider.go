@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps-to-waze-api/models"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	PlacesApiLegacy = "legacy"
+	PlacesApiNew    = "new"
+)
+
+// PlacesProvider looks a place up in one of the Google Places APIs, they
+// are billed differently so each one has its own quota
+type PlacesProvider interface {
+	Name() string
+	Lookup(ctx context.Context, place models.Place) (models.Coordinates, error)
+}
+
+func newPlacesProvider(s *Service, name string) PlacesProvider {
+	if name == PlacesApiNew {
+		return newPlacesApiProvider{service: s}
+	}
+
+	return legacyPlacesApiProvider{service: s}
+}
+
+// legacyPlacesApiProvider calls place/details/json, which takes the CID
+type legacyPlacesApiProvider struct {
+	service *Service
+}
+
+func (legacyPlacesApiProvider) Name() string {
+	return PlacesApiLegacy
+}
+
+func (p legacyPlacesApiProvider) Lookup(ctx context.Context, place models.Place) (models.Coordinates, error) {
+	return p.service.getCoordinatesFromCid(ctx, place.Cid)
+}
+
+// ErrCidNotSupported is returned by Places API (New) lookups of places known
+// only by CID, the new API has no way to look them up. Like any other miss
+// the result is cached.
+var ErrCidNotSupported = fmt.Errorf("%w: Places API (New) can't look up a place without its ftid", errNoMatch)
+
+// newPlacesApiProvider calls Places API (New), which only takes place IDs.
+// They are built from the ftid, places known only by CID fail unless
+// PlacesNewLegacyCidFallback lets the legacy API look them up.
+type newPlacesApiProvider struct {
+	service *Service
+}
+
+func (newPlacesApiProvider) Name() string {
+	return PlacesApiNew
+}
+
+func (p newPlacesApiProvider) Lookup(ctx context.Context, place models.Place) (models.Coordinates, error) {
+	if place.Ftid == "" {
+		if !p.service.Config.PlacesNewLegacyCidFallback {
+			return models.Coordinates{}, fmt.Errorf("place %s: %w", place.Cid, ErrCidNotSupported)
+		}
+		slog.WarnContext(ctx, "no ftid for the place, using the legacy Places API and its quota", "cid", place.Cid)
+		return p.service.getCoordinatesFromCid(ctx, place.Cid)
+	}
+
+	placeId, err := placeIdFromFtid(place.Ftid)
+	if err != nil {
+		return models.Coordinates{}, err
+	}
+
+	return p.service.getCoordinatesFromPlaceId(ctx, placeId)
+}
+
+func (s *Service) getCoordinatesFromPlaceId(ctx context.Context, placeId string) (models.Coordinates, error) {
+	err := s.reservePlacesRequest(ctx, MapsPlacesNewRequestTypeId, s.Config.PlacesNewMaxRequestsPerMonth, s.Config.PlacesNewMaxRequestsPerDay)
+	if err != nil {
+		return models.Coordinates{}, err
+	}
+
+	apiUrl := fmt.Sprintf("https://places.googleapis.com/v1/places/%s", url.PathEscape(placeId))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiUrl, nil)
+	if err != nil {
+		return models.Coordinates{}, fmt.Errorf("failed to create request to API: %w", err)
+	}
+	req.Header.Set("X-Goog-Api-Key", s.Config.MapsAPIKey)
+	// Only the Essentials fields, the others move the request to a pricier SKU
+	req.Header.Set("X-Goog-FieldMask", "id,location")
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return models.Coordinates{}, fmt.Errorf("failed to make the request to API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// The place doesn't exist, asking again won't help
+	if resp.StatusCode == http.StatusNotFound {
+		return models.Coordinates{}, fmt.Errorf("%w: received %s from API", errNoMatch, resp.Status)
+	}
+
+	// Check status code
+	if resp.StatusCode != http.StatusOK {
+		return models.Coordinates{}, fmt.Errorf("received non-OK HTTP status: %s", resp.Status)
+	}
+
+	// Read the response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return models.Coordinates{}, fmt.Errorf("failed to read the response body: %w", err)
+	}
+
+	var placeResp models.GooglePlacesNewResponse
+	if err := json.Unmarshal(body, &placeResp); err != nil {
+		return models.Coordinates{}, fmt.Errorf("failed to unmarshal the response: %w", err)
+	}
+
+	latitude := strconv.FormatFloat(placeResp.Location.Latitude, 'f', -1, 64)
+	longitude := strconv.FormatFloat(placeResp.Location.Longitude, 'f', -1, 64)
+	if !isValidCoordinates(latitude, longitude) || placeResp.Location == (models.GooglePlacesNewLatLng{}) {
+		return models.Coordinates{}, fmt.Errorf("the response has no location for place %s", placeId)
+	}
+
+	return models.Coordinates{Latitude: latitude, Longitude: longitude}, nil
+}
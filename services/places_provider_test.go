@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"maps-to-waze-api/models"
+	"testing"
+)
+
+func TestNewPlacesApiProviderCidOnly(t *testing.T) {
+	s := &Service{}
+	provider := newPlacesProvider(s, PlacesApiNew)
+
+	_, err := provider.Lookup(context.Background(), models.Place{Cid: "12357681832208772627"})
+	if !errors.Is(err, ErrCidNotSupported) || !errors.Is(err, errNoMatch) {
+		t.Errorf("Lookup() error = %v, want %v", err, ErrCidNotSupported)
+	}
+}
+
+func TestPlaceIdFromFtid(t *testing.T) {
+	const ftid = "0x4786c6aec34636a1:0xab7f4e27101a2e13"
+
+	placeId, err := placeIdFromFtid(ftid)
+	if err != nil {
+		t.Fatalf("placeIdFromFtid() failed: %v", err)
+	}
+
+	back, err := ftidFromPlaceId(placeId)
+	if err != nil || back != ftid {
+		t.Errorf("ftidFromPlaceId(%q) = %q, %v, want %q", placeId, back, err, ftid)
+	}
+}
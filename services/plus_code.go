@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps-to-waze-api/internal/olc"
+	"maps-to-waze-api/models"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const ProviderPlusCode = "plus_code"
+
+var (
+	globalPlusCodePattern = regexp.MustCompile(`(?i)(?:^|[^0-9A-Z])([2-9CFGHJMPQRV][2-9CFGHJMPQRVWX0]{7}\+(?:[2-9CFGHJMPQRVWX]{2,7})?)(?:$|[^0-9A-Z])`)
+	shortPlusCodePattern  = regexp.MustCompile(`(?i)(?:^|[^0-9A-Z+])([2-9CFGHJMPQRVWX]{4,6}\+[2-9CFGHJMPQRVWX]{2,3})(?:$|[^0-9A-Z])[\s,]*([^/?&#]*)`)
+)
+
+func findGlobalPlusCode(text string) (string, bool) {
+	match := globalPlusCodePattern.FindStringSubmatch(text)
+	if match == nil || !olc.IsFull(match[1]) {
+		return "", false
+	}
+
+	return strings.ToUpper(match[1]), true
+}
+
+// findShortPlusCode finds codes like "9G8F+6X Zurich" and returns the code
+// together with the locality written after it
+func findShortPlusCode(text string) (string, string, bool) {
+	match := shortPlusCodePattern.FindStringSubmatch(text)
+	if match == nil || !olc.IsShort(match[1]) {
+		return "", "", false
+	}
+
+	return strings.ToUpper(match[1]), strings.TrimSpace(match[2]), true
+}
+
+func plusCodeCandidate(code string) (CoordinateCandidate, error) {
+	area, err := olc.Decode(code)
+	if err != nil {
+		return CoordinateCandidate{}, err
+	}
+	latitude, longitude := area.Center()
+
+	// Half of the longest side of the cell, in meters
+	const metersPerDegree = 111320.0
+	uncertainty := math.Max(
+		(area.LatHi-area.LatLo)*metersPerDegree,
+		(area.LngHi-area.LngLo)*metersPerDegree*math.Cos(latitude*math.Pi/180),
+	) / 2
+
+	return CoordinateCandidate{
+		Coordinates: models.Coordinates{
+			Latitude:  strconv.FormatFloat(math.Round(latitude*1e7)/1e7, 'f', -1, 64),
+			Longitude: strconv.FormatFloat(math.Round(longitude*1e7)/1e7, 'f', -1, 64),
+		},
+		Source:      SourcePlusCode,
+		Uncertainty: &uncertainty,
+	}, nil
+}
+
+// resolveShortPlusCode geocodes the locality and recovers the full code
+// nearest to it
+func (s *Service) resolveShortPlusCode(ctx context.Context, code string, locality string) (CoordinateCandidate, error) {
+	if locality == "" {
+		return CoordinateCandidate{}, fmt.Errorf("%w: the short plus code %s has no locality", errNoMatch, code)
+	}
+
+	results, err := s.geocodeText(ctx, locality, 1, nil)
+	if err != nil {
+		return CoordinateCandidate{}, fmt.Errorf("failed to geocode the locality %q: %w", locality, err)
+	}
+
+	refLat, _ := strconv.ParseFloat(results[0].Coordinates.Latitude, 64)
+	refLng, _ := strconv.ParseFloat(results[0].Coordinates.Longitude, 64)
+	fullCode, err := olc.RecoverNearest(code, refLat, refLng)
+	if err != nil {
+		return CoordinateCandidate{}, err
+	}
+
+	return plusCodeCandidate(fullCode)
+}
+
+func getPlusCodeFromCoordinates(coordinates models.Coordinates) string {
+	latitude, err := strconv.ParseFloat(coordinates.Latitude, 64)
+	if err != nil {
+		return ""
+	}
+	longitude, err := strconv.ParseFloat(coordinates.Longitude, 64)
+	if err != nil {
+		return ""
+	}
+
+	code, err := olc.Encode(latitude, longitude, olc.DefaultCodeLen)
+	if err != nil {
+		return ""
+	}
+
+	return code
+}
+
+// plusCodeExtractor decodes global plus codes offline and resolves short
+// ones against the locality that follows them
+type plusCodeExtractor struct {
+	service *Service
+}
+
+func (plusCodeExtractor) Name() string {
+	return PlusCodeExtractorName
+}
+
+func (e plusCodeExtractor) Extract(ctx context.Context, Url string) ([]CoordinateCandidate, error) {
+	if code, found := findGlobalPlusCode(Url); found {
+		candidate, err := plusCodeCandidate(code)
+		if err != nil {
+			return nil, err
+		}
+		return []CoordinateCandidate{candidate}, nil
+	}
+
+	if code, locality, found := findShortPlusCode(Url); found {
+		candidate, err := e.service.resolveShortPlusCode(ctx, code, locality)
+		if err != nil {
+			return nil, err
+		}
+		return []CoordinateCandidate{candidate}, nil
+	}
+
+	return nil, fmt.Errorf("%w: no plus code in the URL", errNoMatch)
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Short links are resolved by fetching user supplied URLs, so the resolver
+// only talks to map domains on public addresses.
+
+const (
+	maxRedirectHops     = 10
+	maxRedirectBodySize = 2 << 20
+)
+
+var googleHostPattern = regexp.MustCompile(`^([a-z0-9-]+\.)*google\.(com|[a-z]{2}|com?\.[a-z]{2})$`)
+
+var allowedRedirectDomains = []string{
+	"goo.gl",
+	"maps.apple.com",
+	"maps.apple",
+	"osm.org",
+	"openstreetmap.org",
+	"bing.com",
+	"here.com",
+	"waze.com",
+}
+
+var carrierGradeNat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+type HostNotAllowedError struct {
+	Host string
+}
+
+func (e *HostNotAllowedError) Error() string {
+	return fmt.Sprintf("host %q is not an allowed map domain", e.Host)
+}
+
+type UnsupportedSchemeError struct {
+	Scheme string
+}
+
+func (e *UnsupportedSchemeError) Error() string {
+	return fmt.Sprintf("unsupported scheme %q", e.Scheme)
+}
+
+type ForbiddenAddressError struct {
+	Address string
+}
+
+func (e *ForbiddenAddressError) Error() string {
+	return fmt.Sprintf("address %s is not a public address", e.Address)
+}
+
+type TooManyRedirectsError struct {
+	Hops int
+}
+
+func (e *TooManyRedirectsError) Error() string {
+	return fmt.Sprintf("stopped after %d redirects", e.Hops)
+}
+
+type ResponseTooLargeError struct {
+	Size  int64
+	Limit int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("response of %d bytes exceeds the limit of %d bytes", e.Size, e.Limit)
+}
+
+// IsRedirectRejection reports whether err is one of the resolver rejections
+func IsRedirectRejection(err error) bool {
+	var hostErr *HostNotAllowedError
+	var schemeErr *UnsupportedSchemeError
+	var addressErr *ForbiddenAddressError
+	var redirectsErr *TooManyRedirectsError
+	var sizeErr *ResponseTooLargeError
+
+	return errors.As(err, &hostErr) || errors.As(err, &schemeErr) || errors.As(err, &addressErr) ||
+		errors.As(err, &redirectsErr) || errors.As(err, &sizeErr)
+}
+
+// newRedirectClient returns the client used to follow short links. Every
+// hop is checked against the allowlist and every connection against the
+// private ranges, after DNS resolution so rebinding doesn't help.
+func newRedirectClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			return checkDialAddress(address)
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirectHops {
+				return &TooManyRedirectsError{Hops: maxRedirectHops}
+			}
+
+			return validateRedirectUrl(req.URL)
+		},
+	}
+}
+
+func validateRedirectUrl(redirectUrl *url.URL) error {
+	if redirectUrl.Scheme != "http" && redirectUrl.Scheme != "https" {
+		return &UnsupportedSchemeError{Scheme: redirectUrl.Scheme}
+	}
+
+	if port := redirectUrl.Port(); port != "" && port != "80" && port != "443" {
+		return &HostNotAllowedError{Host: redirectUrl.Host}
+	}
+
+	if !isAllowedRedirectHost(redirectUrl.Hostname()) {
+		return &HostNotAllowedError{Host: redirectUrl.Hostname()}
+	}
+
+	return nil
+}
+
+func isAllowedRedirectHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	if googleHostPattern.MatchString(host) {
+		return true
+	}
+
+	for _, domain := range allowedRedirectDomains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func checkDialAddress(address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid address %q: %w", address, err)
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() ||
+		carrierGradeNat.Contains(ip) {
+		return &ForbiddenAddressError{Address: host}
+	}
+
+	return nil
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestCheckDialAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		allowed bool
+	}{
+		{"142.250.180.14:443", true},
+		{"[2a00:1450:4002:402::200e]:443", true},
+		{"127.0.0.1:443", false},
+		{"127.10.0.1:80", false},
+		{"[::1]:443", false},
+		{"10.0.0.1:443", false},
+		{"172.16.5.4:443", false},
+		{"172.31.255.255:443", false},
+		{"192.168.1.1:443", false},
+		{"169.254.169.254:80", false},
+		{"100.64.0.1:443", false},
+		{"100.127.255.254:443", false},
+		{"0.0.0.0:443", false},
+		{"[::]:443", false},
+		{"[fc00::1]:443", false},
+		{"[fd12:3456:789a::1]:443", false},
+		{"[fe80::1]:443", false},
+		{"[::ffff:127.0.0.1]:443", false},
+		{"[::ffff:169.254.169.254]:80", false},
+		{"[::ffff:10.0.0.1]:443", false},
+		{"224.0.0.1:443", false},
+		{"localhost:443", false},
+		{"142.250.180.14", false},
+	}
+
+	for _, test := range tests {
+		err := checkDialAddress(test.address)
+		if test.allowed && err != nil {
+			t.Errorf("checkDialAddress(%q) = %v, want allowed", test.address, err)
+		}
+		if !test.allowed && err == nil {
+			t.Errorf("checkDialAddress(%q) allowed, want an error", test.address)
+		}
+	}
+
+	var addressErr *ForbiddenAddressError
+	if err := checkDialAddress("169.254.169.254:80"); !errors.As(err, &addressErr) {
+		t.Errorf("checkDialAddress() = %v, want a ForbiddenAddressError", err)
+	}
+}
+
+func TestIsAllowedRedirectHost(t *testing.T) {
+	tests := []struct {
+		host    string
+		allowed bool
+	}{
+		{"maps.app.goo.gl", true},
+		{"goo.gl", true},
+		{"www.google.com", true},
+		{"www.google.co.uk", true},
+		{"www.google.com.au", true},
+		{"consent.google.de", true},
+		{"WWW.GOOGLE.COM.", true},
+		{"maps.apple.com", true},
+		{"www.openstreetmap.org", true},
+		{"www.bing.com", true},
+		{"share.here.com", true},
+		{"www.waze.com", true},
+		{"google.com.evil.example", false},
+		{"evilgoogle.com", false},
+		{"google.evil.com", false},
+		{"evilgoo.gl", false},
+		{"goo.gl.evil.example", false},
+		{"apple.com", false},
+		{"maps.apple.com.evil.example", false},
+		{"notwaze.com", false},
+		{"127.0.0.1", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		if got := isAllowedRedirectHost(test.host); got != test.allowed {
+			t.Errorf("isAllowedRedirectHost(%q) = %v, want %v", test.host, got, test.allowed)
+		}
+	}
+}
+
+func TestValidateRedirectUrl(t *testing.T) {
+	tests := []struct {
+		url  string
+		want error
+	}{
+		{url: "https://maps.app.goo.gl/abc"},
+		{url: "https://www.google.com:443/maps"},
+		{url: "http://goo.gl:80/maps/abc"},
+		{url: "https://www.google.com:8443/maps", want: &HostNotAllowedError{}},
+		{url: "http://www.google.com:22/", want: &HostNotAllowedError{}},
+		{url: "https://maps.apple.com:6379/", want: &HostNotAllowedError{}},
+		{url: "https://google.com.evil.example/maps", want: &HostNotAllowedError{}},
+		{url: "ftp://maps.app.goo.gl/abc", want: &UnsupportedSchemeError{}},
+		{url: "file:///etc/passwd", want: &UnsupportedSchemeError{}},
+	}
+
+	for _, test := range tests {
+		parsedUrl, err := url.Parse(test.url)
+		if err != nil {
+			t.Fatalf("url.Parse(%q) failed: %v", test.url, err)
+		}
+
+		err = validateRedirectUrl(parsedUrl)
+		switch test.want.(type) {
+		case nil:
+			if err != nil {
+				t.Errorf("validateRedirectUrl(%q) = %v, want allowed", test.url, err)
+			}
+		case *HostNotAllowedError:
+			var hostErr *HostNotAllowedError
+			if !errors.As(err, &hostErr) {
+				t.Errorf("validateRedirectUrl(%q) = %v, want a HostNotAllowedError", test.url, err)
+			}
+		case *UnsupportedSchemeError:
+			var schemeErr *UnsupportedSchemeError
+			if !errors.As(err, &schemeErr) {
+				t.Errorf("validateRedirectUrl(%q) = %v, want an UnsupportedSchemeError", test.url, err)
+			}
+		}
+	}
+}
+
+// redirectLoop returns a chain of hops redirects ending on a map page
+func redirectLoop(hops int) recordedChain {
+	chain := recordedChain{Url: "https://maps.app.goo.gl/hop0"}
+	for i := 0; i < hops; i++ {
+		chain.Hops = append(chain.Hops, recordedHop{
+			Url:      fmt.Sprintf("https://maps.app.goo.gl/hop%d", i),
+			Status:   http.StatusFound,
+			Location: fmt.Sprintf("https://maps.app.goo.gl/hop%d", i+1),
+		})
+	}
+	chain.Hops = append(chain.Hops, recordedHop{Url: fmt.Sprintf("https://maps.app.goo.gl/hop%d", hops), Status: http.StatusOK})
+
+	return chain
+}
+
+func TestRedirectClientRejections(t *testing.T) {
+	tests := []struct {
+		name  string
+		chain recordedChain
+		check func(error) bool
+	}{
+		{
+			name:  "too many redirects",
+			chain: redirectLoop(maxRedirectHops + 1),
+			check: func(err error) bool {
+				var redirectsErr *TooManyRedirectsError
+				return errors.As(err, &redirectsErr) && redirectsErr.Hops == maxRedirectHops
+			},
+		},
+		{
+			name: "redirect to a lookalike host",
+			chain: recordedChain{
+				Url: "https://maps.app.goo.gl/abc",
+				Hops: []recordedHop{
+					{Url: "https://maps.app.goo.gl/abc", Status: http.StatusFound, Location: "https://google.com.evil.example/maps"},
+				},
+			},
+			check: func(err error) bool {
+				var hostErr *HostNotAllowedError
+				return errors.As(err, &hostErr) && hostErr.Host == "google.com.evil.example"
+			},
+		},
+		{
+			name: "redirect to another port",
+			chain: recordedChain{
+				Url: "https://maps.app.goo.gl/abc",
+				Hops: []recordedHop{
+					{Url: "https://maps.app.goo.gl/abc", Status: http.StatusFound, Location: "https://www.google.com:8443/maps"},
+				},
+			},
+			check: func(err error) bool {
+				var hostErr *HostNotAllowedError
+				return errors.As(err, &hostErr)
+			},
+		},
+		{
+			name: "redirect to another scheme",
+			chain: recordedChain{
+				Url: "https://maps.app.goo.gl/abc",
+				Hops: []recordedHop{
+					{Url: "https://maps.app.goo.gl/abc", Status: http.StatusFound, Location: "gopher://maps.app.goo.gl/abc"},
+				},
+			},
+			check: func(err error) bool {
+				var schemeErr *UnsupportedSchemeError
+				return errors.As(err, &schemeErr)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newTestService(t)
+			s.RedirectClient = replayChain(t, test.chain)
+
+			resolution, err := s.resolveUrl(context.Background(), test.chain.Url)
+
+			var urlErr *url.Error
+			if !errors.As(err, &urlErr) {
+				t.Fatalf("resolveUrl() = %v, %v, want a *url.Error", resolution.Url, err)
+			}
+			if !test.check(err) || !IsRedirectRejection(err) {
+				t.Errorf("resolveUrl() failed with %v", err)
+			}
+		})
+	}
+}
+
+func TestRedirectClientHopLimit(t *testing.T) {
+	s := newTestService(t)
+	s.RedirectClient = replayChain(t, redirectLoop(maxRedirectHops))
+
+	resolution, err := s.resolveUrl(context.Background(), "https://maps.app.goo.gl/hop0")
+	if err != nil {
+		t.Fatalf("resolveUrl() failed after %d redirects: %v", maxRedirectHops, err)
+	}
+	if want := fmt.Sprintf("https://maps.app.goo.gl/hop%d", maxRedirectHops); resolution.Url != want {
+		t.Errorf("resolveUrl() = %s, want %s", resolution.Url, want)
+	}
+}
+
+func TestRedirectClientRefusesPrivateAddresses(t *testing.T) {
+	client := newRedirectClient(5 * time.Second)
+	_, err := client.Get("http://127.0.0.1:1/")
+
+	var urlErr *url.Error
+	var addressErr *ForbiddenAddressError
+	if !errors.As(err, &urlErr) || !errors.As(err, &addressErr) || addressErr.Address != "127.0.0.1" {
+		t.Errorf("Get() = %v, want a ForbiddenAddressError", err)
+	}
+}
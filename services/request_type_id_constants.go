@@ -3,4 +3,5 @@ package services
 const MapsPlacesRequestTypeId = 1
 const GeoapifyStaticMapRequestTypeId = 2
 const GeoapifyReverseGeocodingMapRequestTypeId = 3
-
+const GeoapifyGeocodingRequestTypeId = 4
+const MapsPlacesNewRequestTypeId = 5
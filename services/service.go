@@ -4,18 +4,35 @@ import (
 	"database/sql"
 	"maps-to-waze-api/models"
 	"net/http"
+	"sync"
 )
 
 type Service struct {
-	DB         *sql.DB
-	HTTPClient *http.Client
-	Config     models.Config
+	DB             *sql.DB
+	HTTPClient     *http.Client
+	RedirectClient *http.Client
+	Config         models.Config
+	Extractors     []Extractor
+	Places         PlacesProvider
+
+	// Held while checking a quota and tracking the request, shared by
+	// concurrent conversions
+	quotaMutex sync.Mutex
+
+	resultCache *conversionCache
 }
 
 func NewService(db *sql.DB, client *http.Client, config models.Config) *Service {
-	return &Service{
+	service := &Service{
 		DB:         db,
 		HTTPClient: client,
 		Config:     config,
+
+		resultCache: newConversionCache(config.ConversionCacheSize),
 	}
+	service.RedirectClient = newRedirectClient(client.Timeout)
+	service.Places = newPlacesProvider(service, config.PlacesApi)
+	service.Extractors = service.buildExtractorChain(config.Extractors)
+
+	return service
 }
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+// unavailableDriver fails every connection, like a database that is down.
+// The caches treat that as a miss, so tests run without Postgres.
+type unavailableDriver struct{}
+
+func (unavailableDriver) Open(name string) (driver.Conn, error) {
+	return nil, errors.New("the database is not available in tests")
+}
+
+func init() {
+	sql.Register("unavailable", unavailableDriver{})
+}
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+
+	db, err := sql.Open("unavailable", "")
+	if err != nil {
+		t.Fatalf("failed to open the test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return &Service{DB: db, resultCache: newConversionCache(16)}
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps-to-waze-api/internal/database"
+	"time"
+)
+
+// The cache is an optimization, failures are logged and the link is
+// resolved over the network
+
+func (s *Service) getCachedShortLink(ctx context.Context, shortUrl string) (string, bool) {
+	resolvedUrl, found, err := database.GetShortLink(ctx, s.DB, shortUrl)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to read the short link cache", "error", err)
+		return "", false
+	}
+
+	if found {
+		slog.InfoContext(ctx, fmt.Sprintf("short link found in the cache: %s", shortUrl))
+	}
+
+	return resolvedUrl, found
+}
+
+func (s *Service) cacheShortLink(ctx context.Context, shortUrl string, resolvedUrl string) {
+	err := database.UpsertShortLink(ctx, s.DB, shortUrl, resolvedUrl, s.Config.ShortLinkTTL)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to write the short link cache", "error", err)
+	}
+}
+
+// CleanupCaches deletes the expired short links and conversions every
+// interval until ctx is done
+func (s *Service) CleanupCaches(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := database.DeleteExpiredShortLinks(ctx, s.DB)
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to delete the expired short links", "error", err)
+			} else {
+				slog.InfoContext(ctx, fmt.Sprintf("deleted %d expired short links", deleted))
+			}
+
+			deleted, err = database.DeleteExpiredConversions(ctx, s.DB)
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to delete the expired conversions", "error", err)
+			} else {
+				slog.InfoContext(ctx, fmt.Sprintf("deleted %d expired conversions", deleted))
+			}
+		}
+	}
+}
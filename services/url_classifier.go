@@ -0,0 +1,58 @@
+package services
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	UrlKindPlace      = "place"
+	UrlKindSearch     = "search"
+	UrlKindDirections = "directions"
+	UrlKindStreetView = "street_view"
+	UrlKindEmbed      = "embed"
+	UrlKindList       = "list"
+	UrlKindCid        = "cid"
+	UrlKindUnknown    = "unknown"
+)
+
+// Street View cameras are written as @lat,lng,3a,75y,90t
+var streetViewCameraPattern = regexp.MustCompile(`@-?\d+(\.\d+)?,-?\d+(\.\d+)?,\d+(\.\d+)?a,`)
+
+// classifyUrl tells which kind of Google Maps page a resolved URL points to,
+// links of other providers are only recognized as searches
+func classifyUrl(Url string) string {
+	parsedUrl, err := url.Parse(Url)
+	if err != nil {
+		return UrlKindUnknown
+	}
+	query := parsedUrl.Query()
+
+	if detectProvider(Url) != ProviderGoogle {
+		if query.Get("q") != "" || query.Get("query") != "" || query.Get("address") != "" {
+			return UrlKindSearch
+		}
+		return UrlKindUnknown
+	}
+
+	switch {
+	case strings.Contains(parsedUrl.Path, "/maps/embed") || query.Get("output") == "embed":
+		return UrlKindEmbed
+	case getPlaceListId(Url) != "":
+		return UrlKindList
+	case query.Get("cid") != "":
+		return UrlKindCid
+	case isDirectionsUrl(Url):
+		return UrlKindDirections
+	case streetViewCameraPattern.MatchString(parsedUrl.Path) || query.Get("layer") == "c" ||
+		query.Get("map_action") == "pano":
+		return UrlKindStreetView
+	case strings.Contains(parsedUrl.Path, "/place/"):
+		return UrlKindPlace
+	case strings.Contains(parsedUrl.Path, "/search/") || query.Get("q") != "" || query.Get("query") != "":
+		return UrlKindSearch
+	default:
+		return UrlKindUnknown
+	}
+}
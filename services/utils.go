@@ -3,7 +3,10 @@ package services
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"maps-to-waze-api/internal/database"
+	"os"
+	"strconv"
 )
 
 func (s *Service) checkNumberOfRequestsThisMonth(ctx context.Context, requestTypeId int, multiplier *float64, requestLimit int) (bool, error) {
@@ -33,3 +36,55 @@ func (s *Service) checkNumberOfRequestsToday(ctx context.Context, requestTypeId
 
 	return float64(requests) * (*multiplier) < float64(requestLimit), nil
 }
+
+// checkNumberRequestsGeoapify checks the monthly and daily Geoapify credits
+// for a request type, creditsEnvKey holds the credits spent per request
+func (s *Service) checkNumberRequestsGeoapify(ctx context.Context, requestTypeId int, creditsEnvKey string) bool {
+	creditsPerRequest, err := strconv.ParseFloat(os.Getenv(creditsEnvKey), 64)
+	if err != nil {
+		slog.ErrorContext(ctx, fmt.Sprintf("%s is not set or is not a number", creditsEnvKey))
+		return false
+	}
+
+	monthLimit, err := strconv.Atoi(os.Getenv("GEOAPIFY_MAX_CREDITS_PER_MONTH"))
+	if err != nil {
+		slog.ErrorContext(ctx, "GEOAPIFY_MAX_CREDITS_PER_MONTH is not set or is not an integer")
+		return false
+	}
+
+	canProcede, err := s.checkNumberOfRequestsThisMonth(ctx, requestTypeId, &creditsPerRequest, monthLimit)
+	if err != nil || !canProcede {
+		return false
+	}
+
+	dayLimit, err := strconv.Atoi(os.Getenv("GEOAPIFY_MAX_CREDITS_PER_DAY"))
+	if err != nil {
+		slog.ErrorContext(ctx, "GEOAPIFY_MAX_CREDITS_PER_DAY is not set or is not an integer")
+		return false
+	}
+
+	canProcede, err = s.checkNumberOfRequestsToday(ctx, requestTypeId, &creditsPerRequest, dayLimit)
+	if err != nil || !canProcede {
+		return false
+	}
+
+	return true
+}
+
+// reserveGeoapifyRequest checks the Geoapify credits and tracks the request
+// in one step, so concurrent conversions can't go over the quota together
+func (s *Service) reserveGeoapifyRequest(ctx context.Context, requestTypeId int, creditsEnvKey string) error {
+	s.quotaMutex.Lock()
+	defer s.quotaMutex.Unlock()
+
+	if !s.checkNumberRequestsGeoapify(ctx, requestTypeId, creditsEnvKey) {
+		return fmt.Errorf("number of requests exceeded")
+	}
+
+	requestId := ctx.Value("request_id").(string)
+	if err := database.InsertRequest(ctx, s.DB, requestId, requestTypeId); err != nil {
+		return fmt.Errorf("failed to insert the request in the database: %w", err)
+	}
+
+	return nil
+}